@@ -0,0 +1,739 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-chi/chi/v5"
+)
+
+// AdminTokens maps the bearer tokens of ADMIN_TOKENS to the name of their admin, who is recorded as author of edits.
+var AdminTokens map[string]string
+
+var (
+	errAdminNotFound    = errors.New("not found")
+	errAdminConflict    = errors.New("conflict")
+	errAdminInvalidData = errors.New("invalid data")
+)
+
+// parseAdminTokens parses "name:token,name:token".
+func parseAdminTokens(s string) (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(entry, ":")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected name:token", entry)
+		}
+		if _, exists := tokens[token]; exists {
+			return nil, fmt.Errorf("token of %s is used twice", name)
+		}
+		tokens[token] = name
+	}
+	return tokens, nil
+}
+
+// adminAuth lets requests with a token of ADMIN_TOKENS through and puts the name of the admin into the context.
+func adminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || bearer == "" {
+			writeUnauthorizedResponse(w, "Missing admin token.")
+			return
+		}
+
+		author := ""
+		for token, name := range AdminTokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(bearer)) == 1 {
+				author = name
+			}
+		}
+		if author == "" {
+			writeUnauthorizedResponse(w, "Invalid admin token.")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "author", author)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// AdminEdit tells who edited a row by hand. Imported rows have no author.
+type AdminEdit struct {
+	EditedBy  string     `json:"edited_by,omitempty"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type AdminAlmanax struct {
+	Date        string `json:"date"`
+	BonusId     int64  `json:"bonus_id"`
+	TributeId   int64  `json:"tribute_id"`
+	RewardKamas int64  `json:"reward_kamas"`
+	AdminEdit
+}
+
+type AdminBonusType struct {
+	Id     int64        `json:"id"`
+	NameId string       `json:"name_id"` // derived from the english name on creation, used as bonus type id by the read API
+	Names  Translations `json:"names"`
+	AdminEdit
+}
+
+type AdminBonus struct {
+	Id           int64        `json:"id"`
+	BonusTypeId  int64        `json:"bonus_type_id"`
+	Descriptions Translations `json:"descriptions"`
+	AdminEdit
+}
+
+type AdminTribute struct {
+	Id             int64        `json:"id"`
+	ItemAnkamaId   int64        `json:"item_ankama_id"`
+	ItemNames      Translations `json:"item_names"`
+	ItemIcon       string       `json:"item_icon"`
+	ItemSd         string       `json:"item_sd,omitempty"`
+	ItemHq         string       `json:"item_hq,omitempty"`
+	ItemHd         string       `json:"item_hd,omitempty"`
+	ItemSubtype    string       `json:"item_subtype"`
+	ItemDoduapiUri string       `json:"item_doduapi_uri"`
+	Quantity       int64        `json:"quantity"`
+	AdminEdit
+}
+
+func validateTranslations(field string, translations Translations) error {
+	if translations["en"] == "" {
+		return fmt.Errorf("%w: %s needs an english text", errAdminInvalidData, field)
+	}
+	for lang := range translations {
+		if !sliceContains(Languages, lang) {
+			return fmt.Errorf("%w: unsupported language %q in %s", errAdminInvalidData, lang, field)
+		}
+	}
+	return nil
+}
+
+// rowState reports if the row exists and if it is soft-deleted. table is never user input.
+func rowState(tx *sql.Tx, table string, id int64) (exists bool, deleted bool, err error) {
+	var deletedAt *time.Time
+	err = tx.QueryRow(`SELECT deleted_at FROM `+table+` WHERE id = ?`, id).Scan(&deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, false, nil
+	}
+	return err == nil, deletedAt != nil, err
+}
+
+func requireActive(tx *sql.Tx, table string, id int64, field string) error {
+	exists, deleted, err := rowState(tx, table, id)
+	if err != nil {
+		return err
+	}
+	if !exists || deleted {
+		return fmt.Errorf("%w: %s %d does not exist or is deleted", errAdminInvalidData, field, id)
+	}
+	return nil
+}
+
+// datesUsing returns the active days whose bonus, bonus type or tribute matches the condition.
+func datesUsing(tx *sql.Tx, condition string, id int64) ([]string, error) {
+	rows, err := tx.Query(`
+		SELECT a.date FROM almanax AS a
+		JOIN bonus AS b ON b.id = a.bonus_id
+		WHERE a.deleted_at IS NULL AND `+condition, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []string
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+	return dates, rows.Err()
+}
+
+func softDelete(tx *sql.Tx, table string, id int64, author string) error {
+	_, err := tx.Exec(`UPDATE `+table+` SET deleted_at = datetime('now'), edited_by = ?, edited_at = datetime('now'), updated_at = datetime('now')
+		WHERE id = ?`, author, id)
+	return err
+}
+
+func getAdminAlmanax(tx *sql.Tx, date string) (*AdminAlmanax, int64, error) {
+	var id int64
+	var almanax AdminAlmanax
+	err := tx.QueryRow(`SELECT id, date, bonus_id, tribute_id, COALESCE(reward_kamas, 0), edited_by, edited_at, deleted_at FROM almanax WHERE date = ?`, date).
+		Scan(&id, &almanax.Date, &almanax.BonusId, &almanax.TributeId, &almanax.RewardKamas, &almanax.EditedBy, &almanax.EditedAt, &almanax.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, fmt.Errorf("%w: no almanax on %s", errAdminNotFound, date)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return &almanax, id, nil
+}
+
+func getAdminBonusType(tx *sql.Tx, id int64) (*AdminBonusType, error) {
+	var bonusType AdminBonusType
+	err := tx.QueryRow(`SELECT id, name_id, `+translationsSelect(TranslationEntityBonusType, "bonus_types.id")+`, edited_by, edited_at, deleted_at
+		FROM bonus_types WHERE id = ?`, id).
+		Scan(&bonusType.Id, &bonusType.NameId, &bonusType.Names, &bonusType.EditedBy, &bonusType.EditedAt, &bonusType.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no bonus type %d", errAdminNotFound, id)
+	}
+	return &bonusType, err
+}
+
+func getAdminBonus(tx *sql.Tx, id int64) (*AdminBonus, error) {
+	var bonus AdminBonus
+	err := tx.QueryRow(`SELECT id, bonus_type_id, `+translationsSelect(TranslationEntityBonus, "bonus.id")+`, edited_by, edited_at, deleted_at
+		FROM bonus WHERE id = ?`, id).
+		Scan(&bonus.Id, &bonus.BonusTypeId, &bonus.Descriptions, &bonus.EditedBy, &bonus.EditedAt, &bonus.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no bonus %d", errAdminNotFound, id)
+	}
+	return &bonus, err
+}
+
+func getAdminTribute(tx *sql.Tx, id int64) (*AdminTribute, error) {
+	var tribute AdminTribute
+	err := tx.QueryRow(`SELECT id, item_ankama_id, `+translationsSelect(TranslationEntityTribute, "tribute.id")+`, item_icon,
+			COALESCE(item_sd, ''), COALESCE(item_hq, ''), COALESCE(item_hd, ''), item_subtype, item_doduapi_uri, quantity, edited_by, edited_at, deleted_at
+		FROM tribute WHERE id = ?`, id).
+		Scan(&tribute.Id, &tribute.ItemAnkamaId, &tribute.ItemNames, &tribute.ItemIcon, &tribute.ItemSd, &tribute.ItemHq, &tribute.ItemHd,
+			&tribute.ItemSubtype, &tribute.ItemDoduapiUri, &tribute.Quantity, &tribute.EditedBy, &tribute.EditedAt, &tribute.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no tribute %d", errAdminNotFound, id)
+	}
+	return &tribute, err
+}
+
+// SaveAlmanaxDay creates the day or, with replace, overwrites an existing one and restores it if it was deleted.
+// Only days from today on can be saved, past days are served as immutable.
+func (r *Repository) SaveAlmanaxDay(ctx context.Context, almanax *Almanax, replace bool, today string) (*AdminAlmanax, error) {
+	if _, err := time.Parse(DateLayout, almanax.Date); err != nil {
+		return nil, fmt.Errorf("%w: invalid date, expected yyyy-mm-dd", errAdminInvalidData)
+	}
+	if almanax.Date < today {
+		return nil, fmt.Errorf("%w: %s is in the past, past days are served as immutable", errAdminInvalidData, almanax.Date)
+	}
+
+	var saved *AdminAlmanax
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		if err := requireActive(tx, "bonus", almanax.BonusID, "bonus_id"); err != nil {
+			return err
+		}
+		if err := requireActive(tx, "tribute", almanax.TributeID, "tribute_id"); err != nil {
+			return err
+		}
+
+		_, id, err := getAdminAlmanax(tx, almanax.Date)
+		switch {
+		case errors.Is(err, errAdminNotFound) && !replace:
+			_, err = createAlmanax(tx, almanax)
+		case err != nil:
+			return err
+		case !replace:
+			return fmt.Errorf("%w: there is an almanax on %s, replace it with PUT", errAdminConflict, almanax.Date)
+		default:
+			almanax.ID = id
+			err = updateAlmanax(tx, almanax)
+		}
+		if err != nil {
+			return err
+		}
+
+		saved, _, err = getAdminAlmanax(tx, almanax.Date)
+		return err
+	})
+	return saved, err
+}
+
+func (r *Repository) DeleteAlmanaxDay(ctx context.Context, date, author, today string) error {
+	if date < today {
+		return fmt.Errorf("%w: %s is in the past, past days are served as immutable", errAdminInvalidData, date)
+	}
+	return r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		almanax, id, err := getAdminAlmanax(tx, date)
+		if err != nil {
+			return err
+		}
+		if almanax.DeletedAt != nil {
+			return fmt.Errorf("%w: the almanax on %s is already deleted", errAdminNotFound, date)
+		}
+		return softDelete(tx, "almanax", id, author)
+	})
+}
+
+// SaveBonusType creates the bonus type when bonusType.ID is 0, otherwise it replaces the given names and restores it.
+// It returns the active days showing the bonus type.
+func (r *Repository) SaveBonusType(ctx context.Context, bonusType *BonusType) (*AdminBonusType, []string, error) {
+	if err := validateTranslations("names", bonusType.Names); err != nil {
+		return nil, nil, err
+	}
+
+	var saved *AdminBonusType
+	var dates []string
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		if bonusType.ID == 0 {
+			bonusType.NameID = Slugify(bonusType.Names["en"])
+			var exists int
+			if err = tx.QueryRow(`SELECT COUNT(*) FROM bonus_types WHERE name_id = ?`, bonusType.NameID).Scan(&exists); err != nil {
+				return err
+			}
+			if exists > 0 {
+				return fmt.Errorf("%w: there is a bonus type %s, replace it with PUT", errAdminConflict, bonusType.NameID)
+			}
+			if bonusType.ID, err = createBonusType(tx, bonusType); err != nil {
+				return err
+			}
+		} else {
+			existing, err := getAdminBonusType(tx, bonusType.ID)
+			if err != nil {
+				return err
+			}
+			if existing.DeletedAt != nil {
+				var active int
+				if err = tx.QueryRow(`SELECT COUNT(*) FROM bonus_types WHERE name_id = ? AND deleted_at IS NULL`, existing.NameId).Scan(&active); err != nil {
+					return err
+				}
+				if active > 0 {
+					return fmt.Errorf("%w: an import created the bonus type %s again, edit that one instead", errAdminConflict, existing.NameId)
+				}
+			}
+			if _, err = tx.Exec(`UPDATE bonus_types SET deleted_at = NULL, edited_by = ?, edited_at = datetime('now'), updated_at = datetime('now') WHERE id = ?`,
+				bonusType.EditedBy, bonusType.ID); err != nil {
+				return err
+			}
+			if err = upsertTranslations(tx, TranslationEntityBonusType, bonusType.ID, bonusType.Names); err != nil {
+				return err
+			}
+		}
+
+		if saved, err = getAdminBonusType(tx, bonusType.ID); err != nil {
+			return err
+		}
+		dates, err = datesUsing(tx, "b.bonus_type_id = ?", bonusType.ID)
+		return err
+	})
+	return saved, dates, err
+}
+
+func (r *Repository) DeleteBonusType(ctx context.Context, id int64, author string) error {
+	return r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		if err := requireDeletable(tx, "bonus_types", id); err != nil {
+			return err
+		}
+		var bonuses int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM bonus WHERE bonus_type_id = ? AND deleted_at IS NULL`, id).Scan(&bonuses); err != nil {
+			return err
+		}
+		if bonuses > 0 {
+			return fmt.Errorf("%w: the type still has %d bonuses, delete them first", errAdminConflict, bonuses)
+		}
+		return softDelete(tx, "bonus_types", id, author)
+	})
+}
+
+// SaveBonus creates the bonus when bonus.ID is 0, otherwise it replaces its type and the given descriptions and restores it.
+// It returns the active days showing the bonus.
+func (r *Repository) SaveBonus(ctx context.Context, bonus *Bonus) (*AdminBonus, []string, error) {
+	if err := validateTranslations("descriptions", bonus.Descriptions); err != nil {
+		return nil, nil, err
+	}
+
+	var saved *AdminBonus
+	var dates []string
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		if err := requireActive(tx, "bonus_types", bonus.BonusTypeID, "bonus_type_id"); err != nil {
+			return err
+		}
+
+		var err error
+		if bonus.ID == 0 {
+			if bonus.ID, err = createBonus(tx, bonus); err != nil {
+				return err
+			}
+		} else {
+			if _, err = getAdminBonus(tx, bonus.ID); err != nil {
+				return err
+			}
+			if _, err = tx.Exec(`UPDATE bonus SET bonus_type_id = ?, deleted_at = NULL, edited_by = ?, edited_at = datetime('now'), updated_at = datetime('now') WHERE id = ?`,
+				bonus.BonusTypeID, bonus.EditedBy, bonus.ID); err != nil {
+				return err
+			}
+			if err = upsertTranslations(tx, TranslationEntityBonus, bonus.ID, bonus.Descriptions); err != nil {
+				return err
+			}
+		}
+
+		if saved, err = getAdminBonus(tx, bonus.ID); err != nil {
+			return err
+		}
+		dates, err = datesUsing(tx, "a.bonus_id = ?", bonus.ID)
+		return err
+	})
+	return saved, dates, err
+}
+
+func (r *Repository) DeleteBonus(ctx context.Context, id int64, author string) error {
+	return r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		if err := requireDeletable(tx, "bonus", id); err != nil {
+			return err
+		}
+		dates, err := datesUsing(tx, "a.bonus_id = ?", id)
+		if err != nil {
+			return err
+		}
+		if len(dates) > 0 {
+			return fmt.Errorf("%w: the bonus is still shown on %d days, first on %s", errAdminConflict, len(dates), dates[0])
+		}
+		pinned, err := overridesUsing(tx, "bonus_id", id)
+		if err != nil {
+			return err
+		}
+		if pinned > 0 {
+			return fmt.Errorf("%w: the bonus is still pinned by %d overrides", errAdminConflict, pinned)
+		}
+		return softDelete(tx, "bonus", id, author)
+	})
+}
+
+// SaveTribute creates the tribute when tribute.ID is 0, otherwise it replaces its fields and the given item names and
+// restores it. It returns the active days asking for the tribute.
+func (r *Repository) SaveTribute(ctx context.Context, tribute *Tribute) (*AdminTribute, []string, error) {
+	if err := validateTranslations("item_names", tribute.ItemNames); err != nil {
+		return nil, nil, err
+	}
+	if tribute.ItemAnkamaID <= 0 || tribute.Quantity <= 0 || tribute.ItemIcon == "" {
+		return nil, nil, fmt.Errorf("%w: item_ankama_id, quantity and item_icon are required", errAdminInvalidData)
+	}
+
+	var saved *AdminTribute
+	var dates []string
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		if tribute.ID == 0 {
+			if tribute.ID, err = createTribute(tx, tribute); err != nil {
+				return err
+			}
+		} else {
+			if _, err = getAdminTribute(tx, tribute.ID); err != nil {
+				return err
+			}
+			if _, err = tx.Exec(`
+				UPDATE tribute SET item_icon = ?, item_sd = ?, item_hq = ?, item_hd = ?, item_ankama_id = ?, item_subtype = ?,
+					item_doduapi_uri = ?, quantity = ?, deleted_at = NULL, edited_by = ?, edited_at = datetime('now'), updated_at = datetime('now')
+				WHERE id = ?`,
+				tribute.ItemIcon, tribute.ItemSd, tribute.ItemHq, tribute.ItemHd, tribute.ItemAnkamaID, tribute.ItemSubtype,
+				tribute.ItemDoduapiUri, tribute.Quantity, tribute.EditedBy, tribute.ID); err != nil {
+				return err
+			}
+			if err = upsertTranslations(tx, TranslationEntityTribute, tribute.ID, tribute.ItemNames); err != nil {
+				return err
+			}
+		}
+
+		if saved, err = getAdminTribute(tx, tribute.ID); err != nil {
+			return err
+		}
+		dates, err = datesUsing(tx, "a.tribute_id = ?", tribute.ID)
+		return err
+	})
+	return saved, dates, err
+}
+
+func (r *Repository) DeleteTribute(ctx context.Context, id int64, author string) error {
+	return r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		if err := requireDeletable(tx, "tribute", id); err != nil {
+			return err
+		}
+		dates, err := datesUsing(tx, "a.tribute_id = ?", id)
+		if err != nil {
+			return err
+		}
+		if len(dates) > 0 {
+			return fmt.Errorf("%w: the tribute is still asked for on %d days, first on %s", errAdminConflict, len(dates), dates[0])
+		}
+		pinned, err := overridesUsing(tx, "tribute_id", id)
+		if err != nil {
+			return err
+		}
+		if pinned > 0 {
+			return fmt.Errorf("%w: the tribute is still pinned by %d overrides", errAdminConflict, pinned)
+		}
+		return softDelete(tx, "tribute", id, author)
+	})
+}
+
+func requireDeletable(tx *sql.Tx, table string, id int64) error {
+	exists, deleted, err := rowState(tx, table, id)
+	if err != nil {
+		return err
+	}
+	if !exists || deleted {
+		return fmt.Errorf("%w: no %s %d or it is already deleted", errAdminNotFound, table, id)
+	}
+	return nil
+}
+
+// writeAdminError answers the errors of the admin repository methods.
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errAdminNotFound):
+		writeNotFoundResponse(w, err.Error())
+	case errors.Is(err, errAdminConflict):
+		writeConflictResponse(w, err.Error())
+	case errors.Is(err, errAdminInvalidData):
+		writeInvalidJsonResponse(w, err.Error())
+	default:
+		writeServerErrorResponse(w, "Could not save the change: "+err.Error())
+	}
+}
+
+func decodeAdminRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeInvalidJsonResponse(w, err.Error())
+		return false
+	}
+	return true
+}
+
+func adminIdParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeInvalidQueryResponse(w, "The id has to be a positive number.")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeAdminResponse refreshes caches, metrics and search for the touched dates and answers the saved row.
+func writeAdminResponse(w http.ResponseWriter, r *http.Request, status int, dates []string, v any) {
+	dataChanged(r.Context(), dates)
+	log.FromContext(r.Context()).Info("admin edit", "author", r.Context().Value("author"), "method", r.Method, "path", r.URL.Path, "days", len(dates))
+
+	if v == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := writeJson(w, v); err != nil {
+		log.FromContext(r.Context()).Error("could not encode admin response", "err", err)
+	}
+}
+
+func saveAdminAlmanax(w http.ResponseWriter, r *http.Request, date string, replace bool) {
+	var request AdminAlmanax
+	if !decodeAdminRequest(w, r, &request) {
+		return
+	}
+	if replace {
+		request.Date = date
+	}
+
+	today, err := currentDate("")
+	if err != nil {
+		writeServerErrorResponse(w, "Could not determine today: "+err.Error())
+		return
+	}
+
+	almanax := Almanax{
+		BonusID:     request.BonusId,
+		TributeID:   request.TributeId,
+		Date:        request.Date,
+		RewardKamas: request.RewardKamas,
+		EditedBy:    r.Context().Value("author").(string),
+	}
+	saved, err := Database.SaveAlmanaxDay(r.Context(), &almanax, replace, today.Format(DateLayout))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if replace {
+		status = http.StatusOK
+	}
+	writeAdminResponse(w, r, status, []string{saved.Date}, saved)
+}
+
+func CreateAdminAlmanax(w http.ResponseWriter, r *http.Request) {
+	saveAdminAlmanax(w, r, "", false)
+}
+
+func UpdateAdminAlmanax(w http.ResponseWriter, r *http.Request) {
+	saveAdminAlmanax(w, r, chi.URLParam(r, "date"), true)
+}
+
+func DeleteAdminAlmanax(w http.ResponseWriter, r *http.Request) {
+	date := chi.URLParam(r, "date")
+	today, err := currentDate("")
+	if err != nil {
+		writeServerErrorResponse(w, "Could not determine today: "+err.Error())
+		return
+	}
+	if err := Database.DeleteAlmanaxDay(r.Context(), date, r.Context().Value("author").(string), today.Format(DateLayout)); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminResponse(w, r, http.StatusNoContent, []string{date}, nil)
+}
+
+func saveAdminBonusType(w http.ResponseWriter, r *http.Request, id int64) {
+	var request AdminBonusType
+	if !decodeAdminRequest(w, r, &request) {
+		return
+	}
+
+	bonusType := BonusType{ID: id, Names: request.Names, EditedBy: r.Context().Value("author").(string)}
+	saved, dates, err := Database.SaveBonusType(r.Context(), &bonusType)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if id == 0 {
+		status = http.StatusCreated
+	}
+	writeAdminResponse(w, r, status, dates, saved)
+}
+
+func CreateAdminBonusType(w http.ResponseWriter, r *http.Request) {
+	saveAdminBonusType(w, r, 0)
+}
+
+func UpdateAdminBonusType(w http.ResponseWriter, r *http.Request) {
+	if id, ok := adminIdParam(w, r); ok {
+		saveAdminBonusType(w, r, id)
+	}
+}
+
+func DeleteAdminBonusType(w http.ResponseWriter, r *http.Request) {
+	id, ok := adminIdParam(w, r)
+	if !ok {
+		return
+	}
+	if err := Database.DeleteBonusType(r.Context(), id, r.Context().Value("author").(string)); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminResponse(w, r, http.StatusNoContent, nil, nil)
+}
+
+func saveAdminBonus(w http.ResponseWriter, r *http.Request, id int64) {
+	var request AdminBonus
+	if !decodeAdminRequest(w, r, &request) {
+		return
+	}
+
+	bonus := Bonus{ID: id, BonusTypeID: request.BonusTypeId, Descriptions: request.Descriptions, EditedBy: r.Context().Value("author").(string)}
+	saved, dates, err := Database.SaveBonus(r.Context(), &bonus)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if id == 0 {
+		status = http.StatusCreated
+	}
+	writeAdminResponse(w, r, status, dates, saved)
+}
+
+func CreateAdminBonus(w http.ResponseWriter, r *http.Request) {
+	saveAdminBonus(w, r, 0)
+}
+
+func UpdateAdminBonus(w http.ResponseWriter, r *http.Request) {
+	if id, ok := adminIdParam(w, r); ok {
+		saveAdminBonus(w, r, id)
+	}
+}
+
+func DeleteAdminBonus(w http.ResponseWriter, r *http.Request) {
+	id, ok := adminIdParam(w, r)
+	if !ok {
+		return
+	}
+	if err := Database.DeleteBonus(r.Context(), id, r.Context().Value("author").(string)); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminResponse(w, r, http.StatusNoContent, nil, nil)
+}
+
+func saveAdminTribute(w http.ResponseWriter, r *http.Request, id int64) {
+	var request AdminTribute
+	if !decodeAdminRequest(w, r, &request) {
+		return
+	}
+
+	tribute := Tribute{
+		ID:             id,
+		ItemNames:      request.ItemNames,
+		ItemIcon:       request.ItemIcon,
+		ItemSd:         request.ItemSd,
+		ItemHq:         request.ItemHq,
+		ItemHd:         request.ItemHd,
+		ItemAnkamaID:   request.ItemAnkamaId,
+		ItemSubtype:    request.ItemSubtype,
+		ItemDoduapiUri: request.ItemDoduapiUri,
+		Quantity:       request.Quantity,
+		EditedBy:       r.Context().Value("author").(string),
+	}
+	saved, dates, err := Database.SaveTribute(r.Context(), &tribute)
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if id == 0 {
+		status = http.StatusCreated
+	}
+	writeAdminResponse(w, r, status, dates, saved)
+}
+
+func CreateAdminTribute(w http.ResponseWriter, r *http.Request) {
+	saveAdminTribute(w, r, 0)
+}
+
+func UpdateAdminTribute(w http.ResponseWriter, r *http.Request) {
+	if id, ok := adminIdParam(w, r); ok {
+		saveAdminTribute(w, r, id)
+	}
+}
+
+func DeleteAdminTribute(w http.ResponseWriter, r *http.Request) {
+	id, ok := adminIdParam(w, r)
+	if !ok {
+		return
+	}
+	if err := Database.DeleteTribute(r.Context(), id, r.Context().Value("author").(string)); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminResponse(w, r, http.StatusNoContent, nil, nil)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdminTokens(t *testing.T) {
+	tokens, err := parseAdminTokens("alice:secret1, bob:secret2,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"secret1": "alice", "secret2": "bob"}, tokens)
+
+	tokens, err = parseAdminTokens("")
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	_, err = parseAdminTokens("alice")
+	assert.Error(t, err)
+	_, err = parseAdminTokens("alice:same,bob:same")
+	assert.Error(t, err)
+}
+
+func TestAdminAuth(t *testing.T) {
+	tokens := AdminTokens
+	defer func() { AdminTokens = tokens }()
+	AdminTokens = map[string]string{"secret1": "alice"}
+
+	var author any
+	handler := adminAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		author = r.Context().Value("author")
+	}))
+
+	for header, status := range map[string]int{"": http.StatusUnauthorized, "Bearer wrong": http.StatusUnauthorized, "Bearer secret1": http.StatusOK} {
+		req := httptest.NewRequest("POST", "/admin/almanax", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, status, rec.Code, header)
+	}
+	assert.Equal(t, "alice", author)
+}
+
+func TestWriteAdminError(t *testing.T) {
+	for err, status := range map[error]int{
+		fmt.Errorf("%w: no bonus 3", errAdminNotFound):               http.StatusNotFound,
+		fmt.Errorf("%w: still in use", errAdminConflict):             http.StatusConflict,
+		fmt.Errorf("%w: needs an english text", errAdminInvalidData): http.StatusBadRequest,
+		fmt.Errorf("disk I/O error"):                                 http.StatusInternalServerError,
+	} {
+		rec := httptest.NewRecorder()
+		writeAdminError(rec, err)
+		assert.Equal(t, status, rec.Code, err.Error())
+	}
+}
+
+func TestSaveAlmanaxDayRejectsPastDays(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	_, err := repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 2, "2030-01-01", "2030-01-02"),
+	}, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01")
+	assert.NoError(t, err)
+
+	var bonusId, tributeId int64
+	assert.NoError(t, repo.Db.QueryRow(`SELECT bonus_id, tribute_id FROM almanax WHERE date = '2030-01-01'`).Scan(&bonusId, &tributeId))
+	day := func(date string) *Almanax {
+		return &Almanax{Date: date, BonusID: bonusId, TributeID: tributeId, RewardKamas: 100, EditedBy: "alice"}
+	}
+
+	_, err = repo.SaveAlmanaxDay(ctx, day("2030-01-01"), true, "2030-01-02")
+	assert.ErrorIs(t, err, errAdminInvalidData)
+	_, err = repo.SaveAlmanaxDay(ctx, day("2029-12-31"), false, "2030-01-02")
+	assert.ErrorIs(t, err, errAdminInvalidData)
+	assert.ErrorIs(t, repo.DeleteAlmanaxDay(ctx, "2030-01-01", "alice", "2030-01-02"), errAdminInvalidData)
+
+	var kamas int64
+	var editedBy string
+	assert.NoError(t, repo.Db.QueryRow(`SELECT reward_kamas, edited_by FROM almanax WHERE date = '2030-01-01' AND deleted_at IS NULL`).
+		Scan(&kamas, &editedBy))
+	assert.Zero(t, kamas, "the past day is unchanged")
+	assert.Empty(t, editedBy)
+
+	saved, err := repo.SaveAlmanaxDay(ctx, day("2030-01-02"), true, "2030-01-02")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), saved.RewardKamas, "today can still be corrected")
+	assert.NoError(t, repo.DeleteAlmanaxDay(ctx, "2030-01-02", "alice", "2030-01-02"))
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const apiKeyPrefix = "dodualm_"
+
+// generateApiKey returns a new random key. It is shown once on creation, only its hash is stored.
+func generateApiKey() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(secret), nil
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateApiKey stores a new key for name and returns it in clear text together with the stored row.
+func (r *Repository) CreateApiKey(ctx context.Context, name string, quota RateQuota) (string, *ApiKey, error) {
+	key, err := generateApiKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	apiKey := ApiKey{
+		Name:          name,
+		KeyHash:       hashApiKey(key),
+		KeyPrefix:     key[:len(apiKeyPrefix)+6],
+		RatePerMinute: quota.PerMinute,
+		Burst:         quota.burst(),
+		CreatedAt:     time.Now().UTC(),
+	}
+	err = r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO api_keys (name, key_hash, key_prefix, rate_per_minute, burst, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			apiKey.Name, apiKey.KeyHash, apiKey.KeyPrefix, apiKey.RatePerMinute, apiKey.Burst, apiKey.CreatedAt)
+		if err != nil {
+			return err
+		}
+		apiKey.ID, err = result.LastInsertId()
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return key, &apiKey, nil
+}
+
+// GetApiKeyByHash returns the key with the hash or nil if there is none or it was revoked.
+func (r *Repository) GetApiKeyByHash(ctx context.Context, keyHash string) (*ApiKey, error) {
+	defer observeQuery(ctx, "api_key_by_hash")()
+
+	query := `
+		SELECT id, name, key_hash, key_prefix, rate_per_minute, burst, created_at, last_used_at
+		FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL`
+
+	var apiKey ApiKey
+	err := r.Db.QueryRowContext(ctx, query, keyHash).Scan(&apiKey.ID, &apiKey.Name, &apiKey.KeyHash, &apiKey.KeyPrefix,
+		&apiKey.RatePerMinute, &apiKey.Burst, &apiKey.CreatedAt, &apiKey.LastUsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// ListApiKeys returns all keys including revoked ones with their total number of requests.
+func (r *Repository) ListApiKeys(ctx context.Context) ([]ApiKey, error) {
+	defer observeQuery(ctx, "api_keys")()
+
+	query := `
+		SELECT k.id, k.name, k.key_hash, k.key_prefix, k.rate_per_minute, k.burst, k.created_at, k.last_used_at, k.revoked_at,
+			COALESCE((SELECT SUM(u.requests) FROM api_key_usage u WHERE u.api_key_id = k.id), 0)
+		FROM api_keys k
+		ORDER BY k.id`
+
+	rows, err := r.Db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apiKeys []ApiKey
+	for rows.Next() {
+		var apiKey ApiKey
+		if err := rows.Scan(&apiKey.ID, &apiKey.Name, &apiKey.KeyHash, &apiKey.KeyPrefix, &apiKey.RatePerMinute, &apiKey.Burst,
+			&apiKey.CreatedAt, &apiKey.LastUsedAt, &apiKey.RevokedAt, &apiKey.Requests); err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return apiKeys, rows.Err()
+}
+
+// RevokeApiKey marks the key as revoked, its usage stays for accounting. It returns false if there is no such active key.
+func (r *Repository) RevokeApiKey(ctx context.Context, id int64) (bool, error) {
+	var revoked bool
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now().UTC(), id)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		revoked = affected > 0
+		return err
+	})
+	return revoked, err
+}
+
+// AddApiKeyUsage adds the request counts per key to the daily usage and moves last_used_at forward.
+func (r *Repository) AddApiKeyUsage(ctx context.Context, usage map[int64]int64, at time.Time) error {
+	day := at.UTC().Format(DateLayout)
+	return r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		for id, requests := range usage {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO api_key_usage (api_key_id, day, requests) VALUES (?, ?, ?)
+				ON CONFLICT (api_key_id, day) DO UPDATE SET requests = requests + excluded.requests`,
+				id, day, requests); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ?", at.UTC(), id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type apiKeyEntry struct {
+	apiKey   *ApiKey // nil for unknown or revoked keys
+	loadedAt time.Time
+}
+
+// ApiKeyStore resolves keys from the request header with a short lived cache, so neither valid nor
+// invalid keys hit the database on every request. Keys created or revoked with the cli take effect
+// after TTL. Usage is counted in memory and written by Run.
+type ApiKeyStore struct {
+	mu    sync.Mutex
+	keys  map[string]apiKeyEntry // by key hash
+	usage map[int64]int64
+
+	TTL time.Duration
+
+	load  func(ctx context.Context, keyHash string) (*ApiKey, error)
+	flush func(ctx context.Context, usage map[int64]int64, at time.Time) error
+	now   func() time.Time
+}
+
+var ApiKeys *ApiKeyStore
+
+func NewApiKeyStore(repo *Repository, ttl time.Duration) *ApiKeyStore {
+	return &ApiKeyStore{
+		keys:  make(map[string]apiKeyEntry),
+		usage: make(map[int64]int64),
+		TTL:   ttl,
+		load:  repo.GetApiKeyByHash,
+		flush: repo.AddApiKeyUsage,
+		now:   time.Now,
+	}
+}
+
+// Lookup returns the active key or nil if the key is unknown or revoked.
+func (s *ApiKeyStore) Lookup(ctx context.Context, key string) (*ApiKey, error) {
+	keyHash := hashApiKey(key)
+
+	s.mu.Lock()
+	entry, ok := s.keys[keyHash]
+	s.mu.Unlock()
+	if ok && s.now().Sub(entry.loadedAt) < s.TTL {
+		return entry.apiKey, nil
+	}
+
+	apiKey, err := s.load(ctx, keyHash)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.keys) > 10000 {
+		s.keys = make(map[string]apiKeyEntry) // guessed keys must not grow the cache without bound
+	}
+	s.keys[keyHash] = apiKeyEntry{apiKey: apiKey, loadedAt: s.now()}
+	return apiKey, nil
+}
+
+// Record counts a served request for the key.
+func (s *ApiKeyStore) Record(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usage[id]++
+}
+
+// Flush writes the counted usage. On failure the counts are kept for the next flush.
+func (s *ApiKeyStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	usage := s.usage
+	s.usage = make(map[int64]int64)
+	s.mu.Unlock()
+
+	if len(usage) == 0 {
+		return nil
+	}
+
+	if err := s.flush(ctx, usage, s.now()); err != nil {
+		s.mu.Lock()
+		for id, requests := range usage {
+			s.usage[id] += requests
+		}
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run flushes the usage every interval until ctx is done and a last time before returning.
+func (s *ApiKeyStore) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				log.FromContext(ctx).Error("could not write api key usage", "err", err)
+			}
+		case <-ctx.Done():
+			return s.Flush(context.WithoutCancel(ctx))
+		}
+	}
+}
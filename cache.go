@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// AlmanaxCache is a read-through cache of denormalized almanax days around today.
+// Days outside of the window always go to the database.
+type AlmanaxCache struct {
+	mu         sync.RWMutex
+	days       map[string]*MappedAlmanax // nil value marks a date without data
+	generation uint64                    // bumped on every invalidation to drop concurrent stale loads
+
+	PastDays   int
+	FutureDays int
+
+	load  func(ctx context.Context, from, to string) ([]MappedAlmanax, error)
+	today func() time.Time
+}
+
+var Cache *AlmanaxCache
+
+func NewAlmanaxCache(repo *Repository, pastDays, futureDays int) *AlmanaxCache {
+	return &AlmanaxCache{
+		days:       make(map[string]*MappedAlmanax),
+		PastDays:   pastDays,
+		FutureDays: futureDays,
+		load:       repo.GetAlmanaxByDateRange,
+		today: func() time.Time {
+			today, err := currentDate("")
+			if err != nil {
+				return time.Now()
+			}
+			return today
+		},
+	}
+}
+
+func (c *AlmanaxCache) window() (string, string) {
+	today := c.today()
+	return today.AddDate(0, 0, -c.PastDays).Format(DateLayout), today.AddDate(0, 0, c.FutureDays).Format(DateLayout)
+}
+
+func (c *AlmanaxCache) store(from, to string, almanax []MappedAlmanax, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return // an import invalidated dates while loading
+	}
+
+	start, _ := time.Parse(DateLayout, from)
+	end, _ := time.Parse(DateLayout, to)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		c.days[day.Format(DateLayout)] = nil
+	}
+
+	for i := range almanax {
+		c.days[almanax[i].Almanax.Date] = &almanax[i]
+	}
+}
+
+// Warm loads the whole window and drops days that moved out of it.
+func (c *AlmanaxCache) Warm(ctx context.Context) error {
+	from, to := c.window()
+
+	c.mu.Lock()
+	for date := range c.days {
+		if date < from || date > to {
+			delete(c.days, date)
+		}
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	almanax, err := c.load(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	c.store(from, to, almanax, generation)
+	log.FromContext(ctx).Info("almanax cache warmed", "from", from, "to", to, "days", len(almanax))
+	return nil
+}
+
+// Invalidate drops the given dates, for example the ones touched by an import.
+func (c *AlmanaxCache) Invalidate(dates []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, date := range dates {
+		delete(c.days, date)
+	}
+}
+
+func (c *AlmanaxCache) lookup(from, to string) ([]MappedAlmanax, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	start, err := time.Parse(DateLayout, from)
+	if err != nil {
+		return nil, false
+	}
+	end, err := time.Parse(DateLayout, to)
+	if err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	var result []MappedAlmanax
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		almanax, ok := c.days[day.Format(DateLayout)]
+		if !ok {
+			return nil, false
+		}
+		if almanax != nil && almanax.Override != nil && !almanax.Override.ExpiresAt.After(now) {
+			return nil, false // the override expired, the imported day has to be loaded again
+		}
+		if almanax != nil {
+			result = append(result, *almanax)
+		}
+	}
+
+	return result, true
+}
+
+// Covers reports if the whole range lies inside the cache window.
+func (c *AlmanaxCache) Covers(from, to string) bool {
+	windowFrom, windowTo := c.window()
+	return from >= windowFrom && to <= windowTo
+}
+
+func (c *AlmanaxCache) GetAlmanaxByDateRange(ctx context.Context, from, to string) ([]MappedAlmanax, error) {
+	if !c.Covers(from, to) {
+		return c.load(ctx, from, to)
+	}
+
+	if almanax, ok := c.lookup(from, to); ok {
+		cacheHitsTotal.Inc()
+		return almanax, nil
+	}
+	cacheMissesTotal.Inc()
+
+	c.mu.RLock()
+	generation := c.generation
+	c.mu.RUnlock()
+
+	almanax, err := c.load(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(from, to, almanax, generation)
+	return almanax, nil
+}
+
+func (c *AlmanaxCache) GetAlmanaxByDateRangeAndNameID(ctx context.Context, from, to, nameID string) ([]MappedAlmanax, error) {
+	almanax, err := c.GetAlmanaxByDateRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []MappedAlmanax
+	for _, alm := range almanax {
+		if alm.BonusType.NameID == nameID {
+			result = append(result, alm)
+		}
+	}
+	return result, nil
+}
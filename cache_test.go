@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(loads *int) *AlmanaxCache {
+	today, _ := time.Parse(DateLayout, "2024-06-15")
+	return &AlmanaxCache{
+		days:       make(map[string]*MappedAlmanax),
+		PastDays:   2,
+		FutureDays: 5,
+		today:      func() time.Time { return today },
+		load: func(ctx context.Context, from, to string) ([]MappedAlmanax, error) {
+			*loads++
+			var res []MappedAlmanax
+			for _, date := range []string{"2024-06-10", "2024-06-15", "2024-06-16", "2024-06-18"} {
+				if date >= from && date <= to {
+					var alm MappedAlmanax
+					alm.Almanax.Date = date
+					res = append(res, alm)
+				}
+			}
+			return res, nil
+		},
+	}
+}
+
+func TestCacheReadThrough(t *testing.T) {
+	loads := 0
+	c := newTestCache(&loads)
+
+	res, err := c.GetAlmanaxByDateRange(context.Background(), "2024-06-15", "2024-06-17")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, 1, loads)
+
+	res, _ = c.GetAlmanaxByDateRange(context.Background(), "2024-06-16", "2024-06-17")
+	assert.Len(t, res, 1)
+	assert.Equal(t, 1, loads)
+}
+
+func TestCacheOutsideWindow(t *testing.T) {
+	loads := 0
+	c := newTestCache(&loads)
+
+	c.GetAlmanaxByDateRange(context.Background(), "2024-06-10", "2024-06-10")
+	c.GetAlmanaxByDateRange(context.Background(), "2024-06-10", "2024-06-10")
+	assert.Equal(t, 2, loads)
+}
+
+func TestCacheWarmAndInvalidate(t *testing.T) {
+	loads := 0
+	c := newTestCache(&loads)
+
+	assert.NoError(t, c.Warm(context.Background()))
+	res, _ := c.GetAlmanaxByDateRange(context.Background(), "2024-06-13", "2024-06-20")
+	assert.Len(t, res, 3)
+	assert.Equal(t, 1, loads)
+
+	c.Invalidate([]string{"2024-06-18"})
+	c.GetAlmanaxByDateRange(context.Background(), "2024-06-15", "2024-06-16")
+	assert.Equal(t, 1, loads)
+	c.GetAlmanaxByDateRange(context.Background(), "2024-06-18", "2024-06-18")
+	assert.Equal(t, 2, loads)
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var errCheckFailed = errors.New("check found errors")
+
+const (
+	CheckSeverityError   = "error"
+	CheckSeverityWarning = "warning"
+)
+
+type CheckIssue struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Date     string `json:"date,omitempty"`
+	Entity   string `json:"entity,omitempty"`
+	EntityID int64  `json:"entity_id,omitempty"`
+}
+
+type CheckReport struct {
+	Errors   int          `json:"errors"`
+	Warnings int          `json:"warnings"`
+	Issues   []CheckIssue `json:"issues"`
+}
+
+func (c *CheckReport) add(issue CheckIssue) {
+	if issue.Severity == CheckSeverityError {
+		c.Errors++
+	} else {
+		c.Warnings++
+	}
+	c.Issues = append(c.Issues, issue)
+}
+
+// findMissingDates returns every day between the first and the last of the sorted dates that is not in the list.
+func findMissingDates(dates []string) ([]string, error) {
+	missing := []string{}
+	for i := 1; i < len(dates); i++ {
+		prev, err := time.Parse(DateLayout, dates[i-1])
+		if err != nil {
+			return nil, err
+		}
+		curr, err := time.Parse(DateLayout, dates[i])
+		if err != nil {
+			return nil, err
+		}
+		for day := prev.AddDate(0, 0, 1); day.Before(curr); day = day.AddDate(0, 0, 1) {
+			missing = append(missing, day.Format(DateLayout))
+		}
+	}
+	return missing, nil
+}
+
+func checkMissingDates(ctx context.Context, repo *Repository, report *CheckReport) error {
+	dates, err := repo.GetAlmanaxDates(ctx)
+	if err != nil {
+		return err
+	}
+
+	missing, err := findMissingDates(dates)
+	if err != nil {
+		return err
+	}
+
+	for _, date := range missing {
+		report.add(CheckIssue{
+			Check:    "missing_date",
+			Severity: CheckSeverityError,
+			Message:  "no almanax for this date",
+			Date:     date,
+		})
+	}
+	return nil
+}
+
+func checkBrokenReferences(ctx context.Context, repo *Repository, report *CheckReport) error {
+	query := `
+		SELECT a.id, a.date,
+			CASE WHEN b.id IS NULL THEN 'missing' WHEN b.deleted_at IS NOT NULL THEN 'deleted' ELSE '' END,
+			CASE WHEN bt.id IS NULL THEN 'missing' WHEN bt.deleted_at IS NOT NULL THEN 'deleted' ELSE '' END,
+			CASE WHEN t.id IS NULL THEN 'missing' WHEN t.deleted_at IS NOT NULL THEN 'deleted' ELSE '' END
+		FROM almanax AS a
+		LEFT JOIN bonus AS b ON a.bonus_id = b.id
+		LEFT JOIN bonus_types AS bt ON b.bonus_type_id = bt.id
+		LEFT JOIN tribute AS t ON a.tribute_id = t.id
+		WHERE a.deleted_at IS NULL
+			AND (b.id IS NULL OR b.deleted_at IS NOT NULL
+				OR bt.id IS NULL OR bt.deleted_at IS NOT NULL
+				OR t.id IS NULL OR t.deleted_at IS NOT NULL)
+		ORDER BY a.date ASC`
+
+	rows, err := repo.Db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var date string
+		var states [3]string
+		if err = rows.Scan(&id, &date, &states[0], &states[1], &states[2]); err != nil {
+			return err
+		}
+
+		for i, entity := range []string{"bonus", "bonus_types", "tribute"} {
+			if states[i] == "" {
+				continue
+			}
+			report.add(CheckIssue{
+				Check:    "broken_reference",
+				Severity: CheckSeverityError,
+				Message:  fmt.Sprintf("%s is %s", entity, states[i]),
+				Date:     date,
+				Entity:   "almanax",
+				EntityID: id,
+			})
+		}
+	}
+
+	return rows.Err()
+}
+
+func checkDuplicateBonusDescriptions(ctx context.Context, repo *Repository, report *CheckReport) error {
+	query := `
+		SELECT tr.lang, tr.value, COUNT(DISTINCT b.bonus_type_id)
+		FROM bonus AS b
+		JOIN translations AS tr ON tr.entity = ? AND tr.entity_id = b.id
+		WHERE b.deleted_at IS NULL
+		GROUP BY tr.lang, tr.value
+		HAVING COUNT(DISTINCT b.bonus_type_id) > 1
+		ORDER BY tr.lang, tr.value`
+
+	rows, err := repo.Db.QueryContext(ctx, query, TranslationEntityBonus)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lang, value string
+		var types int
+		if err = rows.Scan(&lang, &value, &types); err != nil {
+			return err
+		}
+		report.add(CheckIssue{
+			Check:    "duplicate_bonus_description",
+			Severity: CheckSeverityWarning,
+			Message:  fmt.Sprintf("description %q (%s) is used by %d bonus types", value, lang, types),
+			Entity:   TranslationEntityBonus,
+		})
+	}
+
+	return rows.Err()
+}
+
+func checkTributes(ctx context.Context, repo *Repository, report *CheckReport) error {
+	query := `
+		SELECT id, quantity, item_icon
+		FROM tribute
+		WHERE deleted_at IS NULL AND (quantity <= 0 OR item_icon IS NULL OR item_icon = '')
+		ORDER BY id`
+
+	rows, err := repo.Db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, quantity int64
+		var icon string
+		if err = rows.Scan(&id, &quantity, &icon); err != nil {
+			return err
+		}
+		if quantity <= 0 {
+			report.add(CheckIssue{
+				Check:    "tribute_quantity",
+				Severity: CheckSeverityError,
+				Message:  fmt.Sprintf("quantity is %d", quantity),
+				Entity:   TranslationEntityTribute,
+				EntityID: id,
+			})
+		}
+		if icon == "" {
+			report.add(CheckIssue{
+				Check:    "tribute_icon",
+				Severity: CheckSeverityError,
+				Message:  "item_icon is empty",
+				Entity:   TranslationEntityTribute,
+				EntityID: id,
+			})
+		}
+	}
+
+	return rows.Err()
+}
+
+func checkTranslations(ctx context.Context, repo *Repository, report *CheckReport) error {
+	for _, entity := range []string{TranslationEntityBonusType, TranslationEntityBonus, TranslationEntityTribute} {
+		for _, lang := range Languages {
+			query := fmt.Sprintf(`
+				SELECT e.id
+				FROM %s AS e
+				LEFT JOIN translations AS tr ON tr.entity = ? AND tr.entity_id = e.id AND tr.lang = ?
+				WHERE e.deleted_at IS NULL AND (tr.value IS NULL OR trim(tr.value) = '')
+				ORDER BY e.id`, entity)
+
+			rows, err := repo.Db.QueryContext(ctx, query, entity, lang)
+			if err != nil {
+				return err
+			}
+
+			for rows.Next() {
+				var id int64
+				if err = rows.Scan(&id); err != nil {
+					rows.Close()
+					return err
+				}
+				report.add(CheckIssue{
+					Check:    "missing_translation",
+					Severity: CheckSeverityWarning,
+					Message:  fmt.Sprintf("no %s translation", lang),
+					Entity:   entity,
+					EntityID: id,
+				})
+			}
+
+			err = rows.Err()
+			rows.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunChecks validates the consistency of the almanax data. Errors are problems that show up as wrong or
+// missing responses, warnings are data quality issues.
+func RunChecks(ctx context.Context, repo *Repository) (*CheckReport, error) {
+	report := &CheckReport{Issues: []CheckIssue{}}
+
+	checks := []func(context.Context, *Repository, *CheckReport) error{
+		checkMissingDates,
+		checkBrokenReferences,
+		checkDuplicateBonusDescriptions,
+		checkTributes,
+		checkTranslations,
+	}
+
+	for _, check := range checks {
+		if err := check(ctx, repo, report); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// runCheck runs the checks and writes the report in the given format. It returns errCheckFailed when
+// the report has errors, the check command exits with status 1 then.
+func runCheck(ctx context.Context, repo *Repository, format string, w io.Writer) error {
+	report, err := RunChecks(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		err = writeJson(w, report)
+	case "text":
+		err = writeCheckReportText(w, report)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.Errors > 0 {
+		return fmt.Errorf("%w: %d errors", errCheckFailed, report.Errors)
+	}
+	return nil
+}
+
+func writeCheckReportText(w io.Writer, report *CheckReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, issue := range report.Issues {
+		subject := issue.Date
+		if issue.EntityID != 0 {
+			subject = strings.TrimSpace(fmt.Sprintf("%s %s#%d", subject, issue.Entity, issue.EntityID))
+		} else if issue.Entity != "" {
+			subject = strings.TrimSpace(subject + " " + issue.Entity)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", issue.Severity, issue.Check, subject, issue.Message)
+	}
+	fmt.Fprintf(tw, "\n%d errors, %d warnings\n", report.Errors, report.Warnings)
+	return tw.Flush()
+}
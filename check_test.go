@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingDates(t *testing.T) {
+	missing, err := findMissingDates([]string{"2024-02-27", "2024-02-28", "2024-03-02", "2024-03-03"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-02-29", "2024-03-01"}, missing)
+
+	missing, err = findMissingDates(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	_, err = findMissingDates([]string{"2024-02-27", "not-a-date"})
+	assert.Error(t, err)
+}
+
+// seedCheckData imports three consecutive days that pass every check.
+func seedCheckData(t *testing.T) *Repository {
+	t.Helper()
+	repo := newTestRepository(t)
+
+	release := []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-01", "2030-01-02"),
+		testMappedAlmanax("Harvest", "More crops", 2, 2, "2030-01-03"),
+	}
+	for i := range release {
+		for _, names := range []map[string]string{release[i].BonusType, release[i].Bonus, release[i].Offering.ItemName} {
+			for _, lang := range Languages {
+				if names[lang] == "" {
+					names[lang] = names["en"] + " " + lang
+				}
+			}
+		}
+	}
+
+	if _, err := repo.ImportAlmanax(context.Background(), release, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func checkTestId(t *testing.T, repo *Repository, query string, args ...any) int64 {
+	t.Helper()
+	var id int64
+	if err := repo.Db.QueryRow(query, args...).Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestRunChecks(t *testing.T) {
+	const harvestDay = `SELECT id FROM almanax WHERE date = '2030-01-03'`
+	const harvestBonus = `SELECT bonus_id FROM almanax WHERE date = '2030-01-03'`
+	const harvestTribute = `SELECT tribute_id FROM almanax WHERE date = '2030-01-03'`
+	const harvestType = `SELECT b.bonus_type_id FROM almanax AS a JOIN bonus AS b ON b.id = a.bonus_id WHERE a.date = '2030-01-03'`
+
+	tests := []struct {
+		name    string
+		corrupt string
+		issues  func(repo *Repository) []CheckIssue
+	}{
+		{
+			name:    "missing date",
+			corrupt: `UPDATE almanax SET deleted_at = datetime('now') WHERE date = '2030-01-02'`,
+			issues: func(repo *Repository) []CheckIssue {
+				return []CheckIssue{{Check: "missing_date", Severity: CheckSeverityError, Message: "no almanax for this date", Date: "2030-01-02"}}
+			},
+		},
+		{
+			name:    "deleted bonus",
+			corrupt: `UPDATE bonus SET deleted_at = datetime('now') WHERE id = (` + harvestBonus + `)`,
+			issues: func(repo *Repository) []CheckIssue {
+				return []CheckIssue{{Check: "broken_reference", Severity: CheckSeverityError, Message: "bonus is deleted",
+					Date: "2030-01-03", Entity: "almanax", EntityID: checkTestId(t, repo, harvestDay)}}
+			},
+		},
+		{
+			name:    "deleted bonus type",
+			corrupt: `UPDATE bonus_types SET deleted_at = datetime('now') WHERE id = (` + harvestType + `)`,
+			issues: func(repo *Repository) []CheckIssue {
+				return []CheckIssue{{Check: "broken_reference", Severity: CheckSeverityError, Message: "bonus_types is deleted",
+					Date: "2030-01-03", Entity: "almanax", EntityID: checkTestId(t, repo, harvestDay)}}
+			},
+		},
+		{
+			name:    "missing tribute",
+			corrupt: `UPDATE almanax SET tribute_id = 999 WHERE date = '2030-01-03'`,
+			issues: func(repo *Repository) []CheckIssue {
+				return []CheckIssue{{Check: "broken_reference", Severity: CheckSeverityError, Message: "tribute is missing",
+					Date: "2030-01-03", Entity: "almanax", EntityID: checkTestId(t, repo, harvestDay)}}
+			},
+		},
+		{
+			name:    "duplicate description",
+			corrupt: `UPDATE translations SET value = 'More' || lang WHERE entity = 'bonus'`,
+			issues: func(repo *Repository) []CheckIssue {
+				var issues []CheckIssue
+				for _, lang := range []string{"de", "en", "es", "fr", "pt"} {
+					issues = append(issues, CheckIssue{Check: "duplicate_bonus_description", Severity: CheckSeverityWarning,
+						Message: fmt.Sprintf(`description "More%s" (%s) is used by 2 bonus types`, lang, lang), Entity: TranslationEntityBonus})
+				}
+				return issues
+			},
+		},
+		{
+			name:    "tribute quantity",
+			corrupt: `UPDATE tribute SET quantity = 0 WHERE item_ankama_id = 2`,
+			issues: func(repo *Repository) []CheckIssue {
+				return []CheckIssue{{Check: "tribute_quantity", Severity: CheckSeverityError, Message: "quantity is 0",
+					Entity: TranslationEntityTribute, EntityID: checkTestId(t, repo, harvestTribute)}}
+			},
+		},
+		{
+			name:    "empty tribute icon",
+			corrupt: `UPDATE tribute SET item_icon = '' WHERE item_ankama_id = 2`,
+			issues: func(repo *Repository) []CheckIssue {
+				return []CheckIssue{{Check: "tribute_icon", Severity: CheckSeverityError, Message: "item_icon is empty",
+					Entity: TranslationEntityTribute, EntityID: checkTestId(t, repo, harvestTribute)}}
+			},
+		},
+		{
+			name:    "empty translation",
+			corrupt: `UPDATE translations SET value = ' ' WHERE entity = 'bonus_types' AND lang = 'de' AND entity_id = (` + harvestType + `)`,
+			issues: func(repo *Repository) []CheckIssue {
+				return []CheckIssue{{Check: "missing_translation", Severity: CheckSeverityWarning, Message: "no de translation",
+					Entity: TranslationEntityBonusType, EntityID: checkTestId(t, repo, harvestType)}}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := seedCheckData(t)
+			expected := test.issues(repo)
+
+			// the missing tribute can only be stored without foreign keys
+			_, err := repo.Writer.Exec(`PRAGMA foreign_keys = OFF`)
+			assert.NoError(t, err)
+			_, err = repo.Writer.Exec(test.corrupt)
+			assert.NoError(t, err)
+
+			report, err := RunChecks(context.Background(), repo)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, report.Issues)
+
+			errorCount := 0
+			for _, issue := range expected {
+				if issue.Severity == CheckSeverityError {
+					errorCount++
+				}
+			}
+			assert.Equal(t, errorCount, report.Errors)
+			assert.Equal(t, len(expected)-errorCount, report.Warnings)
+		})
+	}
+}
+
+func TestRunCheckClean(t *testing.T) {
+	repo := seedCheckData(t)
+
+	var out bytes.Buffer
+	assert.NoError(t, runCheck(context.Background(), repo, "json", &out))
+	assert.JSONEq(t, `{"errors": 0, "warnings": 0, "issues": []}`, out.String())
+}
+
+func TestRunCheckExitStatus(t *testing.T) {
+	repo := seedCheckData(t)
+	_, err := repo.Writer.Exec(`UPDATE tribute SET quantity = 0 WHERE item_ankama_id = 2`)
+	assert.NoError(t, err)
+	_, err = repo.Writer.Exec(`UPDATE translations SET value = '' WHERE entity = 'tribute' AND lang = 'pt' AND entity_id =
+		(SELECT id FROM tribute WHERE item_ankama_id = 1)`)
+	assert.NoError(t, err)
+	tributeId := checkTestId(t, repo, `SELECT id FROM tribute WHERE item_ankama_id = 2`)
+	translationId := checkTestId(t, repo, `SELECT id FROM tribute WHERE item_ankama_id = 1`)
+
+	var out bytes.Buffer
+	err = runCheck(context.Background(), repo, "json", &out)
+	assert.ErrorIs(t, err, errCheckFailed, "errors make the check command exit with status 1")
+
+	var report CheckReport
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, CheckReport{Errors: 1, Warnings: 1, Issues: []CheckIssue{
+		{Check: "tribute_quantity", Severity: CheckSeverityError, Message: "quantity is 0", Entity: TranslationEntityTribute, EntityID: tributeId},
+		{Check: "missing_translation", Severity: CheckSeverityWarning, Message: "no pt translation", Entity: TranslationEntityTribute, EntityID: translationId},
+	}}, report)
+
+	out.Reset()
+	err = runCheck(context.Background(), repo, "text", &out)
+	assert.ErrorIs(t, err, errCheckFailed)
+	assert.Regexp(t, fmt.Sprintf(`error +tribute_quantity +tribute#%d +quantity is 0`, tributeId), out.String())
+	assert.Contains(t, out.String(), "1 errors, 1 warnings")
+
+	// warnings alone do not fail the check
+	_, err = repo.Writer.Exec(`UPDATE tribute SET quantity = 1 WHERE item_ankama_id = 2`)
+	assert.NoError(t, err)
+	out.Reset()
+	assert.NoError(t, runCheck(context.Background(), repo, "json", &out))
+
+	assert.Error(t, runCheck(context.Background(), repo, "yaml", &out))
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	coverageMu     sync.RWMutex
+	cachedCoverage *AlmanaxCoverageResponse // computed after imports and edits by refreshCoverage
+)
+
+// futureDays counts the days after today up to lastDate.
+func futureDays(lastDate string) (int, error) {
+	today, err := currentDate("")
+	if err != nil {
+		return 0, err
+	}
+	last, err := time.Parse(DateLayout, lastDate)
+	if err != nil {
+		return 0, err
+	}
+	todayDate, _ := time.Parse(DateLayout, today.Format(DateLayout))
+	return max(int(last.Sub(todayDate).Hours()/24), 0), nil
+}
+
+func getAlmanaxCoverage(ctx context.Context, repo *Repository) (*AlmanaxCoverageResponse, error) {
+	dates, err := repo.GetAlmanaxDates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missing, err := findMissingDates(dates)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &AlmanaxCoverageResponse{
+		Days:         len(dates),
+		MissingDates: missing,
+	}
+
+	if len(dates) > 0 {
+		coverage.FirstDate = dates[0]
+		coverage.LastDate = dates[len(dates)-1]
+		if coverage.FutureDays, err = futureDays(coverage.LastDate); err != nil {
+			return nil, err
+		}
+	}
+
+	latestImport, err := repo.GetLatestImport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latestImport != nil {
+		coverage.ReleaseTag = latestImport.ReleaseTag
+		coverage.ImportedAt = &latestImport.ImportedAt
+	}
+
+	return coverage, nil
+}
+
+// refreshCoverage computes the coverage after an import or an admin edit, keeps it for
+// RetrieveAlmanaxCoverage and updates the coverage gauges.
+func refreshCoverage(ctx context.Context, repo *Repository) (*AlmanaxCoverageResponse, error) {
+	coverage, err := getAlmanaxCoverage(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	coverageMu.Lock()
+	cachedCoverage = coverage
+	coverageMu.Unlock()
+
+	// an empty database reports 0 instead of keeping the values of deleted days
+	coverageFirstDate.Set(0)
+	if first, err := time.Parse(DateLayout, coverage.FirstDate); err == nil {
+		coverageFirstDate.Set(float64(first.Unix()))
+	}
+	coverageLastDate.Set(0)
+	if last, err := time.Parse(DateLayout, coverage.LastDate); err == nil {
+		coverageLastDate.Set(float64(last.Unix()))
+	}
+	coverageFutureDays.Set(float64(coverage.FutureDays))
+	coverageMissingDays.Set(float64(len(coverage.MissingDates)))
+
+	coverageImportedAt.Set(0)
+	if coverage.ImportedAt != nil {
+		coverageImportedAt.Set(float64(coverage.ImportedAt.Unix()))
+	}
+	coverageRelease.Reset()
+	if coverage.ReleaseTag != "" {
+		coverageRelease.WithLabelValues(coverage.ReleaseTag).Set(1)
+	}
+
+	return coverage, nil
+}
+
+// currentCoverage returns the coverage of the latest refreshCoverage. Only the future days are
+// counted again, they change with the date.
+func currentCoverage(ctx context.Context, repo *Repository) (*AlmanaxCoverageResponse, error) {
+	coverageMu.RLock()
+	cached := cachedCoverage
+	coverageMu.RUnlock()
+
+	if cached == nil {
+		// nothing was imported or edited since the start
+		coverage, err := getAlmanaxCoverage(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		coverageMu.Lock()
+		if cachedCoverage == nil {
+			cachedCoverage = coverage
+		}
+		cached = cachedCoverage
+		coverageMu.Unlock()
+	}
+
+	coverage := *cached
+	if coverage.LastDate != "" {
+		var err error
+		if coverage.FutureDays, err = futureDays(coverage.LastDate); err != nil {
+			return nil, err
+		}
+	}
+	return &coverage, nil
+}
+
+func RetrieveAlmanaxCoverage(w http.ResponseWriter, r *http.Request) {
+	coverage, err := currentCoverage(r.Context(), Database)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not compute coverage: "+err.Error())
+		return
+	}
+
+	var importedAt time.Time
+	if coverage.ImportedAt != nil {
+		importedAt = *coverage.ImportedAt
+	}
+	info := newCacheInfo(importedAt, false, coverage.FirstDate, coverage.LastDate,
+		fmt.Sprint(coverage.Days), fmt.Sprint(coverage.FutureDays), strings.Join(coverage.MissingDates, ","))
+	if WriteCacheHeader(&w, r, info) {
+		return
+	}
+	err = writeJson(w, coverage)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
+}
+
+// meta information about the import the served data comes from
+func RetrieveAlmanaxInfo(w http.ResponseWriter, r *http.Request) {
+	latestImport, err := Database.GetLatestImport(r.Context())
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query imports: "+err.Error())
+		return
+	}
+	if latestImport == nil {
+		writeNotFoundResponse(w, "No almanax data was imported yet.")
+		return
+	}
+
+	res := AlmanaxInfoResponse{
+		ReleaseTag:       latestImport.ReleaseTag,
+		RequestedVersion: latestImport.RequestedVersion,
+		AssetId:          latestImport.AssetId,
+		DownloadedAt:     latestImport.DownloadedAt,
+		ImportedAt:       latestImport.ImportedAt,
+		Days: AlmanaxImportDays{
+			Received:    latestImport.Received,
+			Inserted:    latestImport.Inserted,
+			Updated:     latestImport.Updated,
+			Unchanged:   latestImport.Unchanged,
+			Kept:        latestImport.Kept,
+			SkippedPast: latestImport.SkippedPast,
+		},
+		Overrides: AlmanaxImportOverrides{
+			Agreed:    latestImport.OverridesAgreed,
+			Disagreed: latestImport.OverridesDisagreed,
+		},
+		ImportedBy:    latestImport.DodualmVersion,
+		ServerVersion: DodudaVersion,
+	}
+
+	if WriteCacheHeader(&w, r, newCacheInfo(latestImport.ImportedAt, false, fmt.Sprint(latestImport.ID), DodudaVersion)) {
+		return
+	}
+	err = writeJson(w, res)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
+}
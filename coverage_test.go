@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlmanaxCoverage(t *testing.T) {
+	now, err := currentDate("")
+	assert.NoError(t, err)
+	today, _ := time.Parse(DateLayout, now.Format(DateLayout))
+	day := func(offset int) string {
+		return today.AddDate(0, 0, offset).Format(DateLayout)
+	}
+
+	tests := []struct {
+		name       string
+		days       []string
+		first      string
+		last       string
+		futureDays int
+		missing    []string
+	}{
+		{name: "single day", days: []string{day(10)}, first: day(10), last: day(10), futureDays: 10, missing: []string{}},
+		{name: "gaps", days: []string{day(-2), day(-1), day(1), day(4)}, first: day(-2), last: day(4), futureDays: 4,
+			missing: []string{day(0), day(2), day(3)}},
+		{name: "ends today", days: []string{day(-1), day(0)}, first: day(-1), last: day(0), missing: []string{}},
+		{name: "ends in the past", days: []string{day(-5), day(-4)}, first: day(-5), last: day(-4), missing: []string{}},
+		// runs last, so the gauges of the cases before must not stay
+		{name: "empty database", missing: []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := newTestRepository(t)
+			if len(test.days) > 0 {
+				_, err := repo.ImportAlmanax(context.Background(), []mapping.MappedMultilangNPCAlmanax{
+					testMappedAlmanax("Experience", "More xp", 1, 1, test.days...),
+				}, ImportSource{ReleaseTag: "1.0.0"}, day(-10))
+				assert.NoError(t, err)
+			}
+
+			coverage, err := refreshCoverage(context.Background(), repo)
+			assert.NoError(t, err)
+			assert.Equal(t, test.first, coverage.FirstDate)
+			assert.Equal(t, test.last, coverage.LastDate)
+			assert.Equal(t, len(test.days), coverage.Days)
+			assert.Equal(t, test.futureDays, coverage.FutureDays)
+			assert.Equal(t, test.missing, coverage.MissingDates)
+
+			assert.Equal(t, float64(test.futureDays), testutil.ToFloat64(coverageFutureDays))
+			assert.Equal(t, float64(len(test.missing)), testutil.ToFloat64(coverageMissingDays))
+			var first, last float64
+			if len(test.days) > 0 {
+				firstDate, _ := time.Parse(DateLayout, test.first)
+				lastDate, _ := time.Parse(DateLayout, test.last)
+				first, last = float64(firstDate.Unix()), float64(lastDate.Unix())
+				assert.Equal(t, "1.0.0", coverage.ReleaseTag)
+				assert.Equal(t, 1.0, testutil.ToFloat64(coverageRelease.WithLabelValues("1.0.0")))
+			} else {
+				assert.Empty(t, coverage.ReleaseTag)
+				assert.Nil(t, coverage.ImportedAt)
+				assert.Equal(t, 0, testutil.CollectAndCount(coverageRelease))
+			}
+			assert.Equal(t, first, testutil.ToFloat64(coverageFirstDate))
+			assert.Equal(t, last, testutil.ToFloat64(coverageLastDate))
+		})
+	}
+}
+
+func TestRetrieveAlmanaxCoverage(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	_, err := repo.ImportAlmanax(context.Background(), []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-01", "2030-01-03"),
+	}, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01")
+	assert.NoError(t, err)
+
+	rec := getTestRoute(t, "/dofus3/v1/almanax/coverage")
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	var coverage AlmanaxCoverageResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &coverage))
+	assert.Equal(t, "2030-01-01", coverage.FirstDate)
+	assert.Equal(t, "2030-01-03", coverage.LastDate)
+	assert.Equal(t, 2, coverage.Days)
+	assert.Equal(t, []string{"2030-01-02"}, coverage.MissingDates)
+}
+
+func TestRetrieveAlmanaxCoverageServesRefreshedCoverage(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	ctx := context.Background()
+	release := []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-01", "2030-01-03"),
+	}
+	_, err := repo.ImportAlmanax(ctx, release, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01")
+	assert.NoError(t, err)
+	_, err = refreshCoverage(ctx, repo)
+	assert.NoError(t, err)
+
+	// requests neither scan the database nor touch the gauges
+	coverageMissingDays.Set(42)
+	release[0].Days = []string{"2030-01-02"}
+	_, err = repo.ImportAlmanax(ctx, release, ImportSource{ReleaseTag: "1.0.1"}, "2029-01-01")
+	assert.NoError(t, err)
+
+	var coverage AlmanaxCoverageResponse
+	assert.NoError(t, json.Unmarshal(getTestRoute(t, "/dofus3/v1/almanax/coverage").Body.Bytes(), &coverage))
+	assert.Equal(t, []string{"2030-01-02"}, coverage.MissingDates)
+	assert.Equal(t, "1.0.0", coverage.ReleaseTag)
+	assert.Equal(t, 42.0, testutil.ToFloat64(coverageMissingDays))
+
+	dataChanged(ctx, []string{"2030-01-02"})
+	assert.NoError(t, json.Unmarshal(getTestRoute(t, "/dofus3/v1/almanax/coverage").Body.Bytes(), &coverage))
+	assert.Empty(t, coverage.MissingDates)
+	assert.Equal(t, 3, coverage.Days)
+	assert.Equal(t, "1.0.1", coverage.ReleaseTag)
+	assert.Equal(t, 0.0, testutil.ToFloat64(coverageMissingDays))
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/charmbracelet/log"
 )
@@ -20,8 +22,23 @@ var (
 	ERR_SERVER_ERROR   = "SERVER_ERROR"
 	ERR_SERVER_MESSAGE = "A server error occurred. This is not your fault. Please try again later and contact the administrator."
 
+	ERR_SERVICE_UNAVAILABLE         = "SERVICE_UNAVAILABLE"
+	ERR_SERVICE_UNAVAILABLE_MESSAGE = "A service this endpoint depends on is temporarily unavailable. Please try again later."
+
 	ERR_NOT_FOUND         = "NOT_FOUND"
 	ERR_NOT_FOUND_MESSAGE = "The requested resource was not found."
+
+	ERR_INVALID_LANGUAGE         = "INVALID_LANGUAGE"
+	ERR_INVALID_LANGUAGE_MESSAGE = "The language you requested is not supported. Please use one of the supported language codes."
+
+	ERR_UNAUTHORIZED         = "UNAUTHORIZED"
+	ERR_UNAUTHORIZED_MESSAGE = "You are not allowed to access this resource. Please check your credentials."
+
+	ERR_CONFLICT         = "CONFLICT"
+	ERR_CONFLICT_MESSAGE = "The change conflicts with the current data. Please check the details and try again."
+
+	ERR_TOO_MANY_REQUESTS         = "TOO_MANY_REQUESTS"
+	ERR_TOO_MANY_REQUESTS_MESSAGE = "You sent too many requests. Please wait as long as the Retry-After header says and try again."
 )
 
 type ApiError struct {
@@ -30,16 +47,34 @@ type ApiError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	// RequestId matches the X-Request-Id header and the request_id in the server logs.
+	RequestId string `json:"request_id,omitempty"`
 }
 
 func writeNotFoundResponse(w http.ResponseWriter, details string) {
 	writeErrorResponse(w, http.StatusNotFound, ERR_NOT_FOUND, ERR_NOT_FOUND_MESSAGE, details)
 }
 
+func writeUnauthorizedResponse(w http.ResponseWriter, details string) {
+	writeErrorResponse(w, http.StatusUnauthorized, ERR_UNAUTHORIZED, ERR_UNAUTHORIZED_MESSAGE, details)
+}
+
+func writeConflictResponse(w http.ResponseWriter, details string) {
+	writeErrorResponse(w, http.StatusConflict, ERR_CONFLICT, ERR_CONFLICT_MESSAGE, details)
+}
+
+func writeTooManyRequestsResponse(w http.ResponseWriter, details string) {
+	writeErrorResponse(w, http.StatusTooManyRequests, ERR_TOO_MANY_REQUESTS, ERR_TOO_MANY_REQUESTS_MESSAGE, details)
+}
+
 func writeServerErrorResponse(w http.ResponseWriter, details string) {
 	writeErrorResponse(w, http.StatusInternalServerError, ERR_SERVER_ERROR, ERR_SERVER_MESSAGE, details)
 }
 
+func writeServiceUnavailableResponse(w http.ResponseWriter, details string) {
+	writeErrorResponse(w, http.StatusServiceUnavailable, ERR_SERVICE_UNAVAILABLE, ERR_SERVICE_UNAVAILABLE_MESSAGE, details)
+}
+
 func writeInvalidFilterResponse(w http.ResponseWriter, details string) {
 	writeErrorResponse(w, http.StatusBadRequest, ERR_INVALID_FILTER_VALUE, ERR_INVALID_FILTER_VALUE_MESSAGE, details)
 }
@@ -48,6 +83,11 @@ func writeInvalidQueryResponse(w http.ResponseWriter, details string) {
 	writeErrorResponse(w, http.StatusBadRequest, ERR_INVALID_QUERY_VALUE, ERR_INVALID_QUERY_MESSAGE, details)
 }
 
+func writeInvalidLanguageResponse(w http.ResponseWriter, lang string) {
+	details := fmt.Sprintf("Unsupported language '%s'. Supported languages: %s.", lang, strings.Join(Languages, ", "))
+	writeErrorResponse(w, http.StatusBadRequest, ERR_INVALID_LANGUAGE, ERR_INVALID_LANGUAGE_MESSAGE, details)
+}
+
 func writeInvalidJsonResponse(w http.ResponseWriter, details string) {
 	writeErrorResponse(w, http.StatusBadRequest, ERR_INVALID_JSON_BODY, ERR_INVALID_JSON_MESSAGE, details)
 }
@@ -59,14 +99,16 @@ func writeErrorResponse(w http.ResponseWriter, status int, code, message, detail
 		Code:    code,
 		Message: message,
 		Details: details,
+		// set by requestLogger, error helpers only get the ResponseWriter
+		RequestId: w.Header().Get(RequestIdHeader),
 	}
 
-	if status == http.StatusInternalServerError {
-		log.Error("Internal Server Error", "code", code, "message", message, "details", details)
+	if status == http.StatusInternalServerError || status == http.StatusServiceUnavailable {
+		log.Error("Internal Server Error", "code", code, "message", message, "details", details, "request_id", apiErr.RequestId)
 	}
 
 	if status == http.StatusBadRequest {
-		log.Warn("Bad Request", "code", code, "message", message, "details", details)
+		log.Warn("Bad Request", "code", code, "message", message, "details", details, "request_id", apiErr.RequestId)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
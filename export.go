@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+)
+
+var ExportFormats = []string{"csv", "ndjson"}
+
+type AlmanaxExportRow struct {
+	Date             string `json:"date"`
+	BonusTypeId      string `json:"bonus_type_id"`
+	BonusTypeName    string `json:"bonus_type_name"`
+	BonusDescription string `json:"bonus_description"`
+	TributeName      string `json:"tribute_name"`
+	TributeAnkamaId  int64  `json:"tribute_ankama_id"`
+	TributeQuantity  int64  `json:"tribute_quantity"`
+	RewardKamas      int64  `json:"reward_kamas"`
+	ImageIcon        string `json:"image_icon"`
+	ImageSd          string `json:"image_sd"`
+	ImageHq          string `json:"image_hq"`
+	ImageHd          string `json:"image_hd"`
+}
+
+var almanaxExportColumns = []string{
+	"date", "bonus_type_id", "bonus_type_name", "bonus_description", "tribute_name", "tribute_ankama_id",
+	"tribute_quantity", "reward_kamas", "image_icon", "image_sd", "image_hq", "image_hd",
+}
+
+func renderAlmanaxExportRow(mapped *MappedAlmanax, lang string) AlmanaxExportRow {
+	alm := renderAlmanax(mapped, lang)
+	return AlmanaxExportRow{
+		Date:             alm.Date,
+		BonusTypeId:      alm.Bonus.Type.Id,
+		BonusTypeName:    alm.Bonus.Type.Name,
+		BonusDescription: alm.Bonus.Description,
+		TributeName:      alm.Tribute.Item.Name,
+		TributeAnkamaId:  alm.Tribute.Item.AnkamaId,
+		TributeQuantity:  alm.Tribute.Quantity,
+		RewardKamas:      alm.RewardKamas,
+		ImageIcon:        alm.Tribute.Item.ImageUrls.Icon,
+		ImageSd:          alm.Tribute.Item.ImageUrls.Sd,
+		ImageHq:          alm.Tribute.Item.ImageUrls.Hq,
+		ImageHd:          alm.Tribute.Item.ImageUrls.Hd,
+	}
+}
+
+func (row *AlmanaxExportRow) record() []string {
+	return []string{
+		row.Date, row.BonusTypeId, row.BonusTypeName, row.BonusDescription, row.TributeName,
+		strconv.FormatInt(row.TributeAnkamaId, 10), strconv.FormatInt(row.TributeQuantity, 10),
+		strconv.FormatInt(row.RewardKamas, 10), row.ImageIcon, row.ImageSd, row.ImageHq, row.ImageHd,
+	}
+}
+
+type almanaxExporter interface {
+	Write(row *AlmanaxExportRow) error
+	Flush() error
+}
+
+type csvAlmanaxExporter struct {
+	w *csv.Writer
+}
+
+func (e *csvAlmanaxExporter) Write(row *AlmanaxExportRow) error {
+	return e.w.Write(row.record())
+}
+
+func (e *csvAlmanaxExporter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonAlmanaxExporter struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonAlmanaxExporter) Write(row *AlmanaxExportRow) error {
+	return e.enc.Encode(row)
+}
+
+func (e *ndjsonAlmanaxExporter) Flush() error {
+	return nil
+}
+
+func exportContentType(format string) string {
+	if format == "csv" {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// newAlmanaxExporter writes the csv header right away. The csv starts with a byte order mark and uses
+// CRLF line endings so spreadsheet applications detect the encoding.
+func newAlmanaxExporter(w io.Writer, format string) (almanaxExporter, error) {
+	switch format {
+	case "csv":
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return nil, err
+		}
+		cw := csv.NewWriter(w)
+		cw.UseCRLF = true
+		if err := cw.Write(almanaxExportColumns); err != nil {
+			return nil, err
+		}
+		return &csvAlmanaxExporter{w: cw}, nil
+	case "ndjson":
+		return &ndjsonAlmanaxExporter{enc: json.NewEncoder(w)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown export format %s", format)
+}
+
+func writeAlmanaxExport(ctx context.Context, repo *Repository, exporter almanaxExporter, lang, from, to, bonusType string) error {
+	err := repo.StreamAlmanaxByDateRange(ctx, from, to, bonusType, func(mapped *MappedAlmanax) error {
+		row := renderAlmanaxExportRow(mapped, lang)
+		return exporter.Write(&row)
+	})
+	if err != nil {
+		return err
+	}
+	return exporter.Flush()
+}
+
+// exportAlmanaxFile writes the export of the export command to file, or to stdout when file is empty.
+func exportAlmanaxFile(ctx context.Context, repo *Repository, file, format, lang, from, to, bonusType string) error {
+	out := os.Stdout
+	if file != "" {
+		var err error
+		if out, err = os.Create(file); err != nil {
+			return err
+		}
+	}
+
+	exporter, err := newAlmanaxExporter(out, format)
+	if err == nil {
+		err = writeAlmanaxExport(ctx, repo, exporter, lang, from, to, bonusType)
+	}
+	if file == "" {
+		return err
+	}
+	if err != nil {
+		out.Close()
+		return err
+	}
+	// a failed close can leave a truncated export behind
+	return out.Close()
+}
+
+func ExportAlmanax(w http.ResponseWriter, r *http.Request) {
+	lang := r.Context().Value("lang").(string)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if !sliceContains(ExportFormats, format) {
+		writeInvalidQueryResponse(w, "Invalid format, expected csv or ndjson.")
+		return
+	}
+
+	from, to, err := parseAlmanaxRange(r)
+	if err != nil {
+		writeInvalidQueryResponse(w, err.Error())
+		return
+	}
+	bonusType := r.URL.Query().Get("filter[bonus.type_name]")
+
+	w.Header().Set("Content-Type", exportContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="almanax-%s-%s-%s.%s"`, lang, from, to, format))
+	writeCachingHeaders(w, r, CacheInfo{Immutable: isImmutableRange(r.Context(), Database, from, to)}) // streamed, so there are no validators
+
+	exporter, err := newAlmanaxExporter(w, format)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not start export: "+err.Error())
+		return
+	}
+
+	err = writeAlmanaxExport(r.Context(), Database, exporter, lang, from, to, bonusType)
+	if err != nil {
+		// the status line is already sent, the client sees a truncated body
+		log.FromContext(r.Context()).Error("almanax export failed", "lang", lang, "format", format, "err", err)
+	}
+}
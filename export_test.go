@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/stretchr/testify/assert"
+)
+
+func importExportTestData(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	_, err := repo.ImportAlmanax(context.Background(), []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp, for everyone", 1, 2, "2030-01-01", "2030-01-03"),
+		testMappedAlmanax("Harvest", "More crops", 7, 5, "2030-01-02"),
+	}, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01")
+	assert.NoError(t, err)
+}
+
+func TestExportAlmanaxCsv(t *testing.T) {
+	importExportTestData(t)
+
+	rec := getTestRoute(t, "/dofus3/v1/en/almanax/export?range[start_date]=2030-01-01&range[end_date]=2030-01-03")
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="almanax-en-2030-01-01-2030-01-03.csv"`, rec.Header().Get("Content-Disposition"))
+
+	icon := "https://example.com/item.png"
+	assert.Equal(t, "\uFEFF"+
+		"date,bonus_type_id,bonus_type_name,bonus_description,tribute_name,tribute_ankama_id,tribute_quantity,reward_kamas,image_icon,image_sd,image_hq,image_hd\r\n"+
+		"2030-01-01,experience,Experience,\"More xp, for everyone\",Item,1,2,0,"+icon+",,,\r\n"+
+		"2030-01-02,harvest,Harvest,More crops,Item,7,5,0,"+icon+",,,\r\n"+
+		"2030-01-03,experience,Experience,\"More xp, for everyone\",Item,1,2,0,"+icon+",,,\r\n",
+		rec.Body.String())
+
+	rec = getTestRoute(t, "/dofus3/v1/fr/almanax/export?range[start_date]=2030-01-01&range[end_date]=2030-01-03&filter[bonus.type_name]=harvest")
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\r\n"), "\r\n")
+	assert.Len(t, lines, 2, "header and the harvest day")
+	assert.Equal(t, "2030-01-02,harvest,Harvest fr,More crops fr,Objet,7,5,0,"+icon+",,,", lines[1])
+}
+
+func TestExportAlmanaxNdjson(t *testing.T) {
+	importExportTestData(t)
+
+	rec := getTestRoute(t, "/dofus3/v1/almanax/export?format=ndjson&range[start_date]=2030-01-02&range[end_date]=2030-01-03")
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	var rows []AlmanaxExportRow
+	for _, line := range lines {
+		var row AlmanaxExportRow
+		assert.NoError(t, json.Unmarshal([]byte(line), &row), line)
+		rows = append(rows, row)
+	}
+	assert.Equal(t, AlmanaxExportRow{
+		Date: "2030-01-02", BonusTypeId: "harvest", BonusTypeName: "Harvest", BonusDescription: "More crops",
+		TributeName: "Item", TributeAnkamaId: 7, TributeQuantity: 5, ImageIcon: "https://example.com/item.png",
+	}, rows[0])
+	assert.Equal(t, "2030-01-03", rows[1].Date)
+}
+
+func TestExportAlmanaxInvalidFormat(t *testing.T) {
+	importExportTestData(t)
+
+	rec := httptest.NewRecorder()
+	Router().ServeHTTP(rec, httptest.NewRequest("GET", "/dofus3/v1/en/almanax/export?format=xml", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestExportAlmanaxFile(t *testing.T) {
+	importExportTestData(t)
+
+	file := filepath.Join(t.TempDir(), "almanax.csv")
+	assert.NoError(t, exportAlmanaxFile(context.Background(), Database, file, "csv", "en", "2030-01-01", "2030-01-03", ""))
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	rec := getTestRoute(t, "/dofus3/v1/en/almanax/export?range[start_date]=2030-01-01&range[end_date]=2030-01-03")
+	assert.Equal(t, rec.Body.String(), string(content))
+
+	err = exportAlmanaxFile(context.Background(), Database, file, "xml", "en", "2030-01-01", "2030-01-03", "")
+	assert.EqualError(t, err, "unknown export format xml")
+
+	err = exportAlmanaxFile(context.Background(), Database, filepath.Join(t.TempDir(), "missing", "almanax.csv"),
+		"csv", "en", "2030-01-01", "2030-01-03", "")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
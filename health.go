@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HealthOk       = "ok"
+	HealthDegraded = "degraded"
+	HealthFailed   = "failed"
+)
+
+// migrationFiles only tells the readiness check which schema version this binary expects.
+//
+//go:embed migrations/*.up.sql
+var migrationFiles embed.FS
+
+func latestMigrationVersion() (uint, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(path.Base(entry.Name()), "_")
+		version, err := strconv.ParseUint(prefix, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest, nil
+}
+
+type HealthCheck struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Details  string `json:"details,omitempty"`
+	Required bool   `json:"required"` // a failing optional check only degrades readiness
+}
+
+type ReadinessResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+func checkDatabase(ctx context.Context, repo *Repository) HealthCheck {
+	check := HealthCheck{Name: "database", Required: true, Status: HealthOk}
+	if repo == nil || repo.Db == nil {
+		check.Status, check.Details = HealthFailed, "database is not open"
+		return check
+	}
+	if err := repo.Db.PingContext(ctx); err != nil {
+		check.Status, check.Details = HealthFailed, err.Error()
+	}
+	return check
+}
+
+func checkMigrations(ctx context.Context, repo *Repository) HealthCheck {
+	check := HealthCheck{Name: "migrations", Required: true, Status: HealthOk}
+	if repo == nil || repo.Db == nil {
+		check.Status, check.Details = HealthFailed, "database is not open"
+		return check
+	}
+
+	want, err := latestMigrationVersion()
+	if err != nil {
+		check.Status, check.Details = HealthFailed, err.Error()
+		return check
+	}
+
+	version, dirty, err := repo.GetMigrationVersion(ctx)
+	switch {
+	case err != nil:
+		check.Status, check.Details = HealthFailed, err.Error()
+	case dirty:
+		check.Status, check.Details = HealthFailed, fmt.Sprintf("migration %d is dirty", version)
+	case version != want:
+		check.Status, check.Details = HealthFailed, fmt.Sprintf("schema version is %d, want %d", version, want)
+	}
+	return check
+}
+
+func checkToday(ctx context.Context, repo *Repository) HealthCheck {
+	check := HealthCheck{Name: "today", Required: true, Status: HealthOk}
+	if repo == nil || repo.Db == nil {
+		check.Status, check.Details = HealthFailed, "database is not open"
+		return check
+	}
+
+	today, err := currentDate("")
+	if err != nil {
+		check.Status, check.Details = HealthFailed, err.Error()
+		return check
+	}
+
+	almanax, err := repo.GetAlmanaxByDates(ctx, []string{today.Format(DateLayout)})
+	switch {
+	case err != nil:
+		check.Status, check.Details = HealthFailed, err.Error()
+	case len(almanax) == 0:
+		check.Status, check.Details = HealthFailed, "no almanax for "+today.Format(DateLayout)
+	}
+	return check
+}
+
+func checkSearch(ctx context.Context, search *SearchClient) HealthCheck {
+	check := HealthCheck{Name: "search", Status: HealthOk}
+	if search == nil {
+		check.Status, check.Details = HealthDegraded, "search is not configured"
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, search.Timeout)
+	defer cancel()
+
+	health, err := search.meili.HealthWithContext(ctx)
+	switch {
+	case err != nil:
+		check.Status, check.Details = HealthDegraded, err.Error()
+	case health.Status != "available":
+		check.Status, check.Details = HealthDegraded, "meilisearch reports "+health.Status
+	case search.breaker.State() != BreakerClosed:
+		check.Status, check.Details = HealthDegraded, "circuit breaker is "+search.breaker.State().String()
+	}
+	return check
+}
+
+// overallHealth is failed if a required check failed and degraded if only optional checks did.
+func overallHealth(checks []HealthCheck) string {
+	status := HealthOk
+	for _, check := range checks {
+		switch {
+		case check.Status == HealthOk:
+		case check.Required:
+			return HealthFailed
+		default:
+			status = HealthDegraded
+		}
+	}
+	return status
+}
+
+func readiness(ctx context.Context, repo *Repository, search *SearchClient) ReadinessResponse {
+	checks := []HealthCheck{
+		checkDatabase(ctx, repo),
+		checkMigrations(ctx, repo),
+		checkToday(ctx, repo),
+		checkSearch(ctx, search),
+	}
+	return ReadinessResponse{Status: overallHealth(checks), Checks: checks}
+}
+
+// process is alive, nothing else is checked
+func RetrieveHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	writeJson(w, map[string]string{"status": HealthOk})
+}
+
+// ready unless a required check fails, a degraded service still answers 200
+func RetrieveReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res := readiness(ctx, Database, Search)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if res.Status == HealthFailed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := writeJson(w, res); err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
+}
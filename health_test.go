@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestMigrationVersion(t *testing.T) {
+	version, err := latestMigrationVersion()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, version, uint(3))
+}
+
+func TestOverallHealth(t *testing.T) {
+	ok := HealthCheck{Name: "database", Required: true, Status: HealthOk}
+	searchDown := HealthCheck{Name: "search", Status: HealthDegraded}
+	dbDown := HealthCheck{Name: "database", Required: true, Status: HealthFailed}
+
+	assert.Equal(t, HealthOk, overallHealth([]HealthCheck{ok}))
+	assert.Equal(t, HealthDegraded, overallHealth([]HealthCheck{ok, searchDown}))
+	assert.Equal(t, HealthFailed, overallHealth([]HealthCheck{searchDown, dbDown}))
+}
+
+func TestReadinessWithoutDatabase(t *testing.T) {
+	res := readiness(context.Background(), nil, nil)
+	assert.Equal(t, HealthFailed, res.Status)
+	assert.Len(t, res.Checks, 4)
+	assert.Equal(t, HealthDegraded, res.Checks[3].Status)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// PastMaxAge is used for responses that only contain days before today. Imports never touch them again.
+	PastMaxAge = 365 * 24 * time.Hour
+	// FutureMaxAge is used for everything that can still change with the next import.
+	FutureMaxAge = 5 * time.Minute
+)
+
+var (
+	datasetMu       sync.RWMutex
+	datasetTag      string
+	datasetRevision string // time of the latest admin edit
+)
+
+// ReleaseHeader carries DatasetTag on data responses.
+const ReleaseHeader = "X-Dodualm-Release"
+
+// DatasetTag is the release tag of the latest import. It is part of every ETag, so a new import
+// changes the validators of all responses it could have touched.
+func DatasetTag() string {
+	datasetMu.RLock()
+	defer datasetMu.RUnlock()
+	return datasetTag
+}
+
+func refreshDatasetTag(ctx context.Context, repo *Repository) error {
+	latestImport, err := repo.GetLatestImport(ctx)
+	if err != nil {
+		return err
+	}
+
+	latestEdit, err := repo.GetLatestEdit(ctx)
+	if err != nil {
+		return err
+	}
+
+	datasetMu.Lock()
+	defer datasetMu.Unlock()
+	if latestImport != nil {
+		datasetTag = latestImport.ReleaseTag
+	} else {
+		datasetTag = ""
+	}
+	datasetRevision = latestEdit
+	datasetInfo.Reset()
+	datasetInfo.WithLabelValues(datasetTag).Set(1)
+	return nil
+}
+
+type CacheInfo struct {
+	ETag         string
+	LastModified time.Time
+	Immutable    bool
+}
+
+// newCacheInfo hashes the dataset tag and the time of the latest admin edit together with the given parts
+// into a weak ETag. Weak, because the same data is served with different encodings and compression.
+func newCacheInfo(lastModified time.Time, immutable bool, parts ...string) CacheInfo {
+	datasetMu.RLock()
+	revision := datasetRevision
+	datasetMu.RUnlock()
+
+	h := sha1.New()
+	h.Write([]byte(DatasetTag()))
+	h.Write([]byte(revision))
+	for _, part := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(part))
+	}
+
+	return CacheInfo{
+		ETag:         `W/"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`,
+		LastModified: lastModified,
+		Immutable:    immutable,
+	}
+}
+
+// isPastRange reports if the whole range ends before today in the server timezone.
+func isPastRange(to string) bool {
+	today, err := currentDate("")
+	if err != nil {
+		return false
+	}
+	return to < today.Format(DateLayout)
+}
+
+// isImmutableRange reports if the responses for the range can not change anymore. Besides ending before today,
+// the range has to lie within the coverage without missing dates, because imports still fill in missing past
+// days, and none of its days may have an active override, which changes the day again when it expires.
+func isImmutableRange(ctx context.Context, repo *Repository, from, to string) bool {
+	if !isPastRange(to) {
+		return false
+	}
+
+	coverage, err := currentCoverage(ctx, repo)
+	if err != nil || coverage.FirstDate == "" || from < coverage.FirstDate || to > coverage.LastDate {
+		return false
+	}
+	for _, missing := range coverage.MissingDates {
+		if missing >= from && missing <= to {
+			return false
+		}
+	}
+
+	overrides, err := repo.CountActiveOverrides(ctx, from, to)
+	return err == nil && overrides == 0
+}
+
+// almanaxCacheInfo derives the validators from the updated_at of every day and its override in the response.
+// total covers paginated responses where days outside of the page change the links. The language is
+// part of the ETag because negotiated routes serve several languages under the same url.
+func almanaxCacheInfo(almanax []MappedAlmanax, lang string, immutable bool, total int) CacheInfo {
+	var lastModified time.Time
+	parts := make([]string, 0, len(almanax)+2)
+	parts = append(parts, lang, fmt.Sprint(total))
+	for i := range almanax {
+		updatedAt := almanax[i].Almanax.UpdatedAt
+		if updatedAt.After(lastModified) {
+			lastModified = updatedAt
+		}
+		parts = append(parts, almanax[i].Almanax.Date+"@"+fmt.Sprint(updatedAt.UnixNano()))
+		if override := almanax[i].Override; override != nil {
+			if override.UpdatedAt.After(lastModified) {
+				lastModified = override.UpdatedAt
+			}
+			parts = append(parts, "override"+fmt.Sprint(override.ID)+"@"+fmt.Sprint(override.UpdatedAt.UnixNano()))
+		}
+	}
+
+	return newCacheInfo(lastModified, immutable, parts...)
+}
+
+func bonusTypesCacheInfo(bonusTypes []BonusType, lang string) CacheInfo {
+	var lastModified time.Time
+	parts := make([]string, 0, len(bonusTypes)+1)
+	parts = append(parts, lang)
+	for i := range bonusTypes {
+		updatedAt := bonusTypes[i].UpdatedAt
+		if updatedAt.After(lastModified) {
+			lastModified = updatedAt
+		}
+		parts = append(parts, bonusTypes[i].NameID+"@"+fmt.Sprint(updatedAt.UnixNano()))
+	}
+
+	return newCacheInfo(lastModified, false, parts...)
+}
+
+func etagMatches(header, etag string) bool {
+	weak := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == weak {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified evaluates the conditional request headers. If-None-Match wins over If-Modified-Since (RFC 9110 13.2.2).
+func notModified(r *http.Request, info CacheInfo) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return info.ETag != "" && etagMatches(inm, info.ETag)
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !info.LastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !info.LastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+// writeCachingHeaders sets Cache-Control and the validators. It answers with 304 and returns true
+// if the client copy is still fresh, the handler must not write a body then.
+func writeCachingHeaders(w http.ResponseWriter, r *http.Request, info CacheInfo) bool {
+	if info.Immutable {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(PastMaxAge.Seconds())))
+	} else {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(FutureMaxAge.Seconds())))
+	}
+	if info.ETag != "" {
+		w.Header().Set("ETag", info.ETag)
+	}
+	if !info.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, info) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
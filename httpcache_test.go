@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlmanaxCacheInfo(t *testing.T) {
+	updatedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	almanax := make([]MappedAlmanax, 2)
+	almanax[0].Almanax.Date = "2020-01-01"
+	almanax[0].Almanax.UpdatedAt = updatedAt.Add(-time.Hour)
+	almanax[1].Almanax.Date = "2020-01-02"
+	almanax[1].Almanax.UpdatedAt = updatedAt
+
+	info := almanaxCacheInfo(almanax, "en", true, 2)
+	assert.True(t, info.Immutable)
+	assert.Equal(t, updatedAt, info.LastModified)
+
+	almanax[0].Almanax.UpdatedAt = updatedAt
+	assert.NotEqual(t, info.ETag, almanaxCacheInfo(almanax, "en", true, 2).ETag)
+	assert.NotEqual(t, almanaxCacheInfo(almanax, "en", true, 2).ETag, almanaxCacheInfo(almanax, "fr", true, 2).ETag)
+}
+
+func TestWriteCachingHeaders(t *testing.T) {
+	info := CacheInfo{
+		ETag:         `W/"abc"`,
+		LastModified: time.Date(2024, 6, 1, 12, 0, 0, 500, time.UTC),
+	}
+
+	rec := httptest.NewRecorder()
+	assert.False(t, writeCachingHeaders(rec, httptest.NewRequest("GET", "/", nil), info))
+	assert.Equal(t, "public, max-age=300", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, `W/"abc"`, rec.Header().Get("ETag"))
+	assert.Equal(t, "Sat, 01 Jun 2024 12:00:00 GMT", rec.Header().Get("Last-Modified"))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", `"other", "abc"`)
+	rec = httptest.NewRecorder()
+	assert.True(t, writeCachingHeaders(rec, req, info))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-Modified-Since", "Sat, 01 Jun 2024 12:00:00 GMT")
+	assert.True(t, writeCachingHeaders(httptest.NewRecorder(), req, info))
+
+	// If-None-Match takes precedence over If-Modified-Since
+	req.Header.Set("If-None-Match", `W/"other"`)
+	assert.False(t, writeCachingHeaders(httptest.NewRecorder(), req, info))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-Modified-Since", "Fri, 31 May 2024 12:00:00 GMT")
+	assert.False(t, writeCachingHeaders(httptest.NewRecorder(), req, info))
+
+	rec = httptest.NewRecorder()
+	writeCachingHeaders(rec, httptest.NewRequest("GET", "/", nil), CacheInfo{Immutable: true})
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	assert.Empty(t, rec.Header().Get("ETag"))
+}
+
+func TestReleaseHeader(t *testing.T) {
+	datasetMu.Lock()
+	previous := datasetTag
+	datasetTag = "1.2.3"
+	datasetMu.Unlock()
+	defer func() {
+		datasetMu.Lock()
+		datasetTag = previous
+		datasetMu.Unlock()
+	}()
+
+	rec := httptest.NewRecorder()
+	releaseHeader(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "1.2.3", rec.Header().Get(ReleaseHeader))
+}
+
+func TestIsImmutableRange(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	now, err := currentDate("")
+	assert.NoError(t, err)
+	today, _ := time.Parse(DateLayout, now.Format(DateLayout))
+	day := func(offset int) string {
+		return today.AddDate(0, 0, offset).Format(DateLayout)
+	}
+
+	_, err = repo.ImportAlmanax(context.Background(), []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, day(-5), day(-4), day(-2), day(-1), day(0)),
+	}, ImportSource{ReleaseTag: "1.0.0"}, day(-10))
+	assert.NoError(t, err)
+
+	immutable := func(from, to string) bool {
+		return isImmutableRange(context.Background(), repo, from, to)
+	}
+	assert.True(t, immutable(day(-5), day(-4)))
+	assert.True(t, immutable(day(-2), day(-1)))
+	assert.False(t, immutable(day(-5), day(-1)), "an import can still fill the gap")
+	assert.False(t, immutable(day(-6), day(-4)), "an import can still add days before the first one")
+	assert.False(t, immutable(day(-1), day(0)))
+
+	rec := getTestRoute(t, fmt.Sprintf("/dofus3/v1/en/almanax?range[start_date]=%s&range[end_date]=%s", day(-5), day(-1)))
+	assert.Equal(t, "public, max-age=300", rec.Header().Get("Cache-Control"))
+	rec = getTestRoute(t, fmt.Sprintf("/dofus3/v1/en/almanax?range[start_date]=%s&range[end_date]=%s", day(-5), day(-4)))
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+
+	_, err = repo.Writer.Exec(`
+		INSERT INTO overrides (date, bonus_id, reason, expires_at, edited_by, edited_at, created_at, updated_at)
+		SELECT date, bonus_id, 'event', datetime('now', '+1 day'), 'alice', datetime('now'), datetime('now'), datetime('now')
+		FROM almanax WHERE date = ?`, day(-1))
+	assert.NoError(t, err)
+	assert.False(t, immutable(day(-2), day(-1)), "the day changes again when the override expires")
+
+	_, err = repo.Writer.Exec(`UPDATE overrides SET expires_at = datetime('now', '-1 second')`)
+	assert.NoError(t, err)
+	assert.True(t, immutable(day(-2), day(-1)))
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/log"
+	mapping "github.com/dofusdude/dodumap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ImportSource describes the release asset an import was loaded from.
+type ImportSource struct {
+	RequestedVersion string
+	ReleaseTag       string
+	AssetId          int64
+	DownloadedAt     time.Time
+}
+
+type ImportResult struct {
+	ReleaseTag string
+	Received   int // days in the release, invalid dates included
+	Inserted   int
+	Updated    int
+	Unchanged  int
+	Kept       int // days that differ from the release but were edited by an admin
+	// past days that differ from the release, they are served as immutable and never changed
+	SkippedPast int
+	// active overrides whose date is in the release, by whether the release has the pinned bonus and tribute
+	OverridesAgreed    int
+	OverridesDisagreed int
+	Dates              []string // dates that were inserted or updated
+}
+
+func bonusTypeFromMapped(alm *mapping.MappedMultilangNPCAlmanax) BonusType {
+	return BonusType{
+		NameID: Slugify(alm.BonusType["en"]),
+		Names:  Translations(alm.BonusType),
+	}
+}
+
+func bonusFromMapped(alm *mapping.MappedMultilangNPCAlmanax) Bonus {
+	return Bonus{
+		Descriptions: Translations(alm.Bonus),
+	}
+}
+
+func tributeFromMapped(alm *mapping.MappedMultilangNPCAlmanax) Tribute {
+	// the mapped almanax does not carry the item category, so subtype and doduapi uri stay empty
+	return Tribute{
+		ItemNames:    Translations(alm.Offering.ItemName),
+		ItemIcon:     alm.Offering.ImageUrls.Icon,
+		ItemSd:       alm.Offering.ImageUrls.SD,
+		ItemHq:       alm.Offering.ImageUrls.HQ,
+		ItemHd:       alm.Offering.ImageUrls.HD,
+		ItemAnkamaID: int64(alm.Offering.ItemId),
+		Quantity:     int64(alm.Offering.Quantity),
+	}
+}
+
+func getOrCreateBonusType(tx *sql.Tx, bonusType *BonusType) (int64, error) {
+	var id int64
+	var editedBy string
+	err := tx.QueryRow(`SELECT id, edited_by FROM bonus_types WHERE name_id = ? AND deleted_at IS NULL`, bonusType.NameID).Scan(&id, &editedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return createBonusType(tx, bonusType)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if editedBy != "" {
+		return id, nil // names edited by an admin win over the release
+	}
+	// new languages get added to known bonus types
+	return id, upsertTranslations(tx, TranslationEntityBonusType, id, bonusType.Names)
+}
+
+func getOrCreateBonus(tx *sql.Tx, bonus *Bonus) (int64, error) {
+	var id int64
+	var editedBy string
+	err := tx.QueryRow(`
+		SELECT b.id, b.edited_by FROM bonus AS b
+		JOIN translations AS tr ON tr.entity = ? AND tr.entity_id = b.id AND tr.lang = 'en'
+		WHERE b.bonus_type_id = ? AND tr.value = ? AND b.deleted_at IS NULL`,
+		TranslationEntityBonus, bonus.BonusTypeID, bonus.Descriptions["en"]).Scan(&id, &editedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return createBonus(tx, bonus)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if editedBy != "" {
+		return id, nil
+	}
+	return id, upsertTranslations(tx, TranslationEntityBonus, id, bonus.Descriptions)
+}
+
+func getOrCreateTribute(tx *sql.Tx, tribute *Tribute) (int64, error) {
+	var id int64
+	var editedBy string
+	err := tx.QueryRow(`SELECT id, edited_by FROM tribute WHERE item_ankama_id = ? AND quantity = ? AND deleted_at IS NULL`,
+		tribute.ItemAnkamaID, tribute.Quantity).Scan(&id, &editedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return createTribute(tx, tribute)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if editedBy != "" {
+		return id, nil
+	}
+	return id, upsertTranslations(tx, TranslationEntityTribute, id, tribute.ItemNames)
+}
+
+// activeOverrides returns the overrides that are not deleted or expired by date.
+func activeOverrides(tx *sql.Tx) (map[string]*Override, error) {
+	rows, err := tx.Query(`SELECT id, date, bonus_id, tribute_id, reason FROM overrides WHERE deleted_at IS NULL AND expires_at > datetime('now')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make(map[string]*Override)
+	for rows.Next() {
+		var override Override
+		if err := rows.Scan(&override.ID, &override.Date, &override.BonusID, &override.TributeID, &override.Reason); err != nil {
+			return nil, err
+		}
+		overrides[override.Date] = &override
+	}
+	return overrides, rows.Err()
+}
+
+// compareOverride records on the override if the release has the pinned bonus and tribute for its date.
+func compareOverride(ctx context.Context, tx *sql.Tx, override *Override, almanax *Almanax, releaseTag string, result *ImportResult) error {
+	agrees := (override.BonusID == nil || *override.BonusID == almanax.BonusID) &&
+		(override.TributeID == nil || *override.TributeID == almanax.TributeID)
+
+	logger := log.FromContext(ctx).With("day", almanax.Date, "override", override.ID, "reason", override.Reason)
+	if agrees {
+		logger.Info("release agrees with override")
+		result.OverridesAgreed++
+	} else {
+		logger.Warn("release disagrees with override", "release_bonus", almanax.BonusID, "release_tribute", almanax.TributeID)
+		result.OverridesDisagreed++
+	}
+
+	_, err := tx.Exec(`UPDATE overrides SET release_tag = ?, release_agrees = ? WHERE id = ?`, releaseTag, agrees, override.ID)
+	return err
+}
+
+// ImportAlmanax writes the mapped almanax into the database in a single transaction.
+// Missing days are inserted, days from today on are updated when they changed. Past days are never touched.
+// Days and texts edited by an admin are kept, differing days are counted and logged as kept. A day showing an edited
+// bonus or tribute counts as edited, the release would otherwise point it at a fresh copy of the unedited row.
+// Active overrides stay in place, the import reports if the release agrees with them.
+// Cancelling ctx rolls the whole import back.
+func (r *Repository) ImportAlmanax(ctx context.Context, data []mapping.MappedMultilangNPCAlmanax, source ImportSource, today string) (*ImportResult, error) {
+	defer observeQuery(ctx, "import_almanax")()
+
+	result := &ImportResult{ReleaseTag: source.ReleaseTag}
+
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		overrides, err := activeOverrides(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := range data {
+			alm := &data[i]
+			result.Received += len(alm.Days)
+
+			bonusType := bonusTypeFromMapped(alm)
+			bonusTypeId, err := getOrCreateBonusType(tx, &bonusType)
+			if err != nil {
+				return err
+			}
+
+			bonus := bonusFromMapped(alm)
+			bonus.BonusTypeID = bonusTypeId
+			bonusId, err := getOrCreateBonus(tx, &bonus)
+			if err != nil {
+				return err
+			}
+
+			tribute := tributeFromMapped(alm)
+			tributeId, err := getOrCreateTribute(tx, &tribute)
+			if err != nil {
+				return err
+			}
+
+			for _, day := range alm.Days {
+				if _, err := time.Parse(DateLayout, day); err != nil {
+					log.FromContext(ctx).Warn("skipping invalid almanax day", "day", day, "receiver", alm.OfferingReceiver)
+					continue
+				}
+
+				almanax := Almanax{
+					BonusID:     bonusId,
+					TributeID:   tributeId,
+					Date:        day,
+					RewardKamas: int64(alm.RewardKamas),
+				}
+
+				if override, ok := overrides[day]; ok {
+					if err = compareOverride(ctx, tx, override, &almanax, source.ReleaseTag, result); err != nil {
+						return err
+					}
+				}
+
+				// edited bonuses and tributes no longer match the release, so their days count as edited too
+				var existing Almanax
+				var bonusEditedBy, tributeEditedBy string
+				err = tx.QueryRow(`
+					SELECT a.id, a.bonus_id, a.tribute_id, a.reward_kamas, a.deleted_at, a.edited_by, b.edited_by, t.edited_by
+					FROM almanax AS a
+					JOIN bonus AS b ON b.id = a.bonus_id
+					JOIN tribute AS t ON t.id = a.tribute_id
+					WHERE a.date = ?`, day).
+					Scan(&existing.ID, &existing.BonusID, &existing.TributeID, &existing.RewardKamas, &existing.DeletedAt, &existing.EditedBy,
+						&bonusEditedBy, &tributeEditedBy)
+				if errors.Is(err, sql.ErrNoRows) {
+					if _, err = createAlmanax(tx, &almanax); err != nil {
+						return err
+					}
+					result.Inserted++
+					result.Dates = append(result.Dates, day)
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				if existing.DeletedAt == nil && existing.BonusID == almanax.BonusID &&
+					existing.TributeID == almanax.TributeID && existing.RewardKamas == almanax.RewardKamas {
+					result.Unchanged++
+					continue
+				}
+
+				if day < today {
+					log.FromContext(ctx).Warn("release differs from a past almanax day, keeping the stored day", "day", day,
+						"release_bonus", almanax.BonusID, "release_tribute", almanax.TributeID, "deleted", existing.DeletedAt != nil)
+					result.SkippedPast++
+					continue
+				}
+
+				if existing.EditedBy != "" || bonusEditedBy != "" || tributeEditedBy != "" {
+					log.FromContext(ctx).Warn("keeping manually edited almanax day", "day", day, "edited_by", existing.EditedBy,
+						"bonus_edited_by", bonusEditedBy, "tribute_edited_by", tributeEditedBy, "deleted", existing.DeletedAt != nil)
+					result.Kept++
+					continue
+				}
+
+				almanax.ID = existing.ID
+				if err = updateAlmanax(tx, &almanax); err != nil {
+					return err
+				}
+				result.Updated++
+				result.Dates = append(result.Dates, day)
+			}
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO imports (release_tag, requested_version, asset_id, downloaded_at, received, inserted, updated, unchanged, kept, skipped_past,
+				overrides_agreed, overrides_disagreed, dodualm_version, imported_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
+			result.ReleaseTag, source.RequestedVersion, source.AssetId, source.DownloadedAt.UTC(), result.Received,
+			result.Inserted, result.Updated, result.Unchanged, result.Kept, result.SkippedPast,
+			result.OverridesAgreed, result.OverridesDisagreed, DodudaVersion)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// importAlmanax downloads the mapped almanax for the given release and imports it.
+func importAlmanax(ctx context.Context, version string) (result *ImportResult, err error) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "almanax import", trace.WithAttributes(attribute.String("dodualm.version.requested", version)))
+	defer func() {
+		observeImport(start, result, err)
+		endSpan(span, err)
+	}()
+	logger := log.FromContext(ctx)
+
+	almanaxData, source, err := loadAlmanaxData(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Almanax data loaded", "count", len(almanaxData), "release", source.ReleaseTag, "asset", source.AssetId)
+
+	today, err := currentDate("")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = Database.ImportAlmanax(ctx, almanaxData, source, today.Format(DateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Almanax data imported", "inserted", result.Inserted, "updated", result.Updated, "unchanged", result.Unchanged, "kept", result.Kept,
+		"skipped_past", result.SkippedPast,
+		"overrides_agreed", result.OverridesAgreed, "overrides_disagreed", result.OverridesDisagreed)
+
+	dataChanged(ctx, result.Dates)
+
+	return result, nil
+}
+
+// dataChanged refreshes everything derived from the database after an import or an admin edit touched the dates.
+func dataChanged(ctx context.Context, dates []string) {
+	logger := log.FromContext(ctx)
+
+	if Cache != nil {
+		Cache.Invalidate(dates)
+		if err := Cache.Warm(ctx); err != nil {
+			logger.Warn("could not warm almanax cache", "err", err)
+		}
+	}
+
+	if err := refreshDatasetTag(ctx, Database); err != nil {
+		logger.Warn("could not refresh dataset tag", "err", err)
+	}
+
+	if _, err := refreshCoverage(ctx, Database); err != nil {
+		logger.Warn("could not refresh coverage", "err", err)
+	}
+
+	if Search != nil {
+		// Meilisearch must not hold back the change, failures are logged per language
+		Jobs.Go(ctx, "search index sync", func(ctx context.Context) error {
+			_, err := SyncSearchIndexes(ctx, Database, Search.meili)
+			return err
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMappedAlmanax(bonusType, bonus string, itemId, quantity int, days ...string) mapping.MappedMultilangNPCAlmanax {
+	var alm mapping.MappedMultilangNPCAlmanax
+	alm.Days = days
+	alm.BonusType = map[string]string{"en": bonusType, "fr": bonusType + " fr"}
+	alm.Bonus = map[string]string{"en": bonus, "fr": bonus + " fr"}
+	alm.Offering.ItemId = itemId
+	alm.Offering.ItemName = map[string]string{"en": "Item", "fr": "Objet"}
+	alm.Offering.Quantity = quantity
+	alm.Offering.ImageUrls.Icon = "https://example.com/item.png"
+	return alm
+}
+
+func TestImportSkipsDeletedBonusType(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	source := ImportSource{ReleaseTag: "1.0.0"}
+
+	_, err := repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-01"),
+	}, source, "2029-01-01")
+	assert.NoError(t, err)
+
+	var deletedId int64
+	assert.NoError(t, repo.Db.QueryRow(`SELECT id FROM bonus_types WHERE name_id = 'experience'`).Scan(&deletedId))
+	_, err = repo.Writer.Exec(`UPDATE bonus_types SET deleted_at = datetime('now') WHERE id = ?`, deletedId)
+	assert.NoError(t, err)
+
+	_, err = repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-02"),
+	}, source, "2029-01-01")
+	assert.NoError(t, err)
+
+	var activeId int64
+	assert.NoError(t, repo.Db.QueryRow(`SELECT id FROM bonus_types WHERE name_id = 'experience' AND deleted_at IS NULL`).Scan(&activeId))
+	assert.NotEqual(t, deletedId, activeId, "a deleted bonus type must not be reused")
+
+	var bonusTypeId int64
+	assert.NoError(t, repo.Db.QueryRow(`SELECT b.bonus_type_id FROM almanax AS a JOIN bonus AS b ON b.id = a.bonus_id WHERE a.date = '2030-01-02'`).
+		Scan(&bonusTypeId))
+	assert.Equal(t, activeId, bonusTypeId)
+}
+
+func TestImportCountsReceivedDays(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	source := ImportSource{ReleaseTag: "1.0.0"}
+
+	_, err := repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-01", "2030-01-02", "2030-01-03"),
+		testMappedAlmanax("Harvest", "More crops", 2, 1, "2030-01-04"),
+	}, source, "2029-01-01")
+	assert.NoError(t, err)
+	_, err = repo.Writer.Exec(`UPDATE almanax SET reward_kamas = 100, edited_by = 'alice' WHERE date = '2030-01-04'`)
+	assert.NoError(t, err)
+
+	// past, changed, unchanged, edited and new days in two offering groups
+	result, err := repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-03"),
+		testMappedAlmanax("Harvest", "More crops", 2, 1, "2030-01-01", "2030-01-02", "2030-01-04", "2030-01-05"),
+	}, source, "2030-01-02")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Inserted)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, 1, result.Kept)
+	assert.Equal(t, 1, result.SkippedPast)
+	assert.Equal(t, 5, result.Received)
+	assert.Equal(t, result.Inserted+result.Updated+result.Unchanged+result.Kept+result.SkippedPast, result.Received)
+
+	imp, err := repo.GetLatestImport(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(result.Received), imp.Received, "days are stored, not offering groups")
+	assert.Equal(t, int64(result.SkippedPast), imp.SkippedPast)
+}
+
+func TestImportSkipsDifferingPastDays(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	source := ImportSource{ReleaseTag: "1.0.0"}
+
+	_, err := repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-01", "2030-01-02"),
+	}, source, "2029-01-01")
+	assert.NoError(t, err)
+
+	result, err := repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-01"),
+		testMappedAlmanax("Experience", "More xp", 1, 5, "2030-01-02"),
+	}, source, "2030-01-03")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, 1, result.SkippedPast)
+	assert.Equal(t, 0, result.Updated)
+	assert.Empty(t, result.Dates)
+
+	var quantity int
+	assert.NoError(t, repo.Db.QueryRow(`SELECT t.quantity FROM almanax AS a JOIN tribute AS t ON t.id = a.tribute_id WHERE a.date = '2030-01-02'`).
+		Scan(&quantity))
+	assert.Equal(t, 1, quantity, "past days are never changed")
+}
+
+func TestImportKeepsEditedBonusAndTribute(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	source := ImportSource{ReleaseTag: "1.0.0"}
+	release := []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 2, "2030-01-01", "2030-01-02"),
+	}
+
+	_, err := repo.ImportAlmanax(ctx, release, source, "2029-01-01")
+	assert.NoError(t, err)
+
+	var bonusId, bonusTypeId, tributeId int64
+	assert.NoError(t, repo.Db.QueryRow(`SELECT a.bonus_id, b.bonus_type_id, a.tribute_id FROM almanax AS a JOIN bonus AS b ON b.id = a.bonus_id WHERE a.date = '2030-01-01'`).
+		Scan(&bonusId, &bonusTypeId, &tributeId))
+
+	// the fields the import looks bonuses and tributes up by
+	_, _, err = repo.SaveBonus(ctx, &Bonus{ID: bonusId, BonusTypeID: bonusTypeId, Descriptions: Translations{"en": "Even more xp"}, EditedBy: "alice"})
+	assert.NoError(t, err)
+	_, _, err = repo.SaveTribute(ctx, &Tribute{ID: tributeId, ItemAnkamaID: 1, Quantity: 3, ItemIcon: "https://example.com/item.png",
+		ItemNames: Translations{"en": "Item"}, EditedBy: "alice"})
+	assert.NoError(t, err)
+
+	result, err := repo.ImportAlmanax(ctx, release, source, "2029-01-01")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Kept)
+	assert.Zero(t, result.Updated)
+
+	for _, day := range []string{"2030-01-01", "2030-01-02"} {
+		almanax, err := repo.GetAlmanaxByDates(ctx, []string{day})
+		assert.NoError(t, err)
+		if assert.Len(t, almanax, 1, day) {
+			assert.Equal(t, bonusId, almanax[0].Bonus.ID, day)
+			assert.Equal(t, "Even more xp", almanax[0].Bonus.Descriptions["en"], day)
+			assert.Equal(t, tributeId, almanax[0].Tribute.ID, day)
+			assert.Equal(t, int64(3), almanax[0].Tribute.Quantity, day)
+		}
+	}
+}
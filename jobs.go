@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// JobGroup runs background jobs like imports and search syncs that outlive the request starting them.
+// On shutdown their shared context is cancelled and the group waits for them to return.
+type JobGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	closed  bool
+	running sync.WaitGroup
+}
+
+var Jobs = NewJobGroup(context.Background())
+
+func NewJobGroup(parent context.Context) *JobGroup {
+	ctx, cancel := context.WithCancel(parent)
+	return &JobGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go starts fn in the background. It returns false without starting fn once the group is shutting down.
+// The job does not inherit the cancellation of parent, only its logger and trace, so it logs with the
+// request id and shows up in the trace of the request that started it.
+func (g *JobGroup) Go(parent context.Context, name string, fn func(ctx context.Context) error) bool {
+	logger := log.FromContext(parent).With("job", name)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		logger.Warn("not starting background job, shutting down")
+		return false
+	}
+
+	g.running.Add(1)
+	go func() {
+		defer g.running.Done()
+		ctx := trace.ContextWithSpanContext(log.WithContext(g.ctx, logger), trace.SpanContextFromContext(parent))
+		if err := fn(ctx); err != nil {
+			logger.Error("background job failed", "err", err)
+		}
+	}()
+	return true
+}
+
+// Shutdown cancels the running jobs and waits until they returned or ctx is done.
+func (g *JobGroup) Shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
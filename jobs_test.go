@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobGroupShutdown(t *testing.T) {
+	jobs := NewJobGroup(context.Background())
+
+	stopped := make(chan struct{})
+	assert.True(t, jobs.Go(context.Background(), "wait", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	}))
+
+	assert.NoError(t, jobs.Shutdown(context.Background()))
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("shutdown returned before the job stopped")
+	}
+
+	assert.False(t, jobs.Go(context.Background(), "late", func(ctx context.Context) error { return nil }))
+}
+
+func TestJobGroupShutdownDeadline(t *testing.T) {
+	jobs := NewJobGroup(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+	jobs.Go(context.Background(), "stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, jobs.Shutdown(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIdHeader = "X-Request-Id"
+
+// configureLogging applies LOG_LEVEL and LOG_FORMAT (text, json or logfmt) to the default logger.
+// Loggers derived from it afterwards inherit both.
+func configureLogging(level, format string) error {
+	parsedLevel, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(parsedLevel)
+
+	switch strings.ToLower(format) {
+	case "", "text":
+		log.SetFormatter(log.TextFormatter)
+	case "json":
+		log.SetFormatter(log.JSONFormatter)
+	case "logfmt":
+		log.SetFormatter(log.LogfmtFormatter)
+	default:
+		return fmt.Errorf("unknown log format %q, use text, json or logfmt", format)
+	}
+	return nil
+}
+
+// requestLogger logs one line per request. It runs after middleware.RequestID and tracingMiddleware,
+// answers the request id in X-Request-Id and puts a logger carrying it and the trace id into the context,
+// so handlers and the jobs they start log with it.
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestId := middleware.GetReqID(r.Context())
+		w.Header().Set(RequestIdHeader, requestId)
+
+		logger := log.With("request_id", requestId)
+		if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+			logger = logger.With("trace_id", spanContext.TraceID().String())
+		}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(log.WithContext(r.Context(), logger)))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		logger.Info("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", chi.RouteContext(r.Context()).RoutePattern(),
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"duration", time.Since(start),
+			"remote", r.RemoteAddr)
+	})
+}
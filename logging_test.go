@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	defer configureLogging("info", "text")
+
+	assert.NoError(t, configureLogging("debug", "json"))
+	assert.Equal(t, log.DebugLevel, log.GetLevel())
+	assert.Error(t, configureLogging("info", "xml"))
+	assert.Error(t, configureLogging("loud", "text"))
+}
+
+func TestRequestIdInErrors(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(requestLogger)
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		writeInvalidQueryResponse(w, "broken")
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIdHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, "abc-123", rec.Header().Get(RequestIdHeader))
+	var apiErr ApiError
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&apiErr))
+	assert.Equal(t, "abc-123", apiErr.RequestId)
+}
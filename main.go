@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/golang-migrate/migrate"
@@ -30,6 +35,21 @@ var (
 	ApiHostName string
 	MeiliHost   string
 	MeiliKey    string
+	UpdateToken string
+
+	MeiliTimeout    time.Duration
+	ShutdownTimeout time.Duration
+
+	TracingExporter     string
+	TracingOtlpEndpoint string
+	TracingSampleRatio  float64
+
+	CachePastDays   int
+	CacheFutureDays int
+
+	DefaultRateQuota  RateQuota
+	ApiKeyCacheTtl    time.Duration
+	TrustProxyHeaders bool
 
 	Database *Repository
 
@@ -65,12 +85,87 @@ var (
 		Long:  `Command to upgrade database`,
 		Run:   migrateUp,
 	}
+
+	generateStaticCmd = &cobra.Command{
+		Use:   "generate-static",
+		Short: "Render the read-only API into static files.",
+		Long:  `Writes per-day and per-month almanax JSON, the bonus listing, the ics and rss feeds and an index manifest for every language.`,
+		Run:   generateStatic,
+	}
+
+	checkCmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check the almanax data for integrity problems.",
+		Long:  `Reports missing dates, broken references, duplicate bonus descriptions, invalid tributes and missing translations. Exits non-zero when errors are found.`,
+		Run:   checkCommand,
+	}
+
+	showCmd = &cobra.Command{
+		Use:   "show [date|from..to]",
+		Short: "Show almanax days from the local database.",
+		Long:  `Prints the almanax for today, a date or a range of dates (yyyy-mm-dd..yyyy-mm-dd) as table, json or csv.`,
+		Args:  cobra.MaximumNArgs(1),
+		Run:   showCommand,
+	}
+
+	tuiCmd = &cobra.Command{
+		Use:   "tui",
+		Short: "Browse the almanax calendar in the terminal.",
+		Long:  `Interactive month view of the local almanax database with a detail pane, bonus filter and language toggle.`,
+		Run:   tuiCommand,
+	}
+
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export almanax days as csv or ndjson.",
+		Long:  `Streams almanax days from the local database with the same columns as the export endpoint.`,
+		Run:   exportCommand,
+	}
+
+	meiliCmd = &cobra.Command{
+		Use:   "meili",
+		Short: "Manage the Meilisearch indexes.",
+	}
+
+	apiKeyCmd = &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage the API keys.",
+	}
+
+	apiKeyCreateCmd = &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an API key with its own rate quota.",
+		Long:  `Prints the new key once, only its hash is stored. The server picks it up after API_KEY_CACHE_TTL.`,
+		Args:  cobra.ExactArgs(1),
+		Run:   apiKeyCreateCommand,
+	}
+
+	apiKeyListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the API keys with their quota and usage.",
+		Run:   apiKeyListCommand,
+	}
+
+	apiKeyRevokeCmd = &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke an API key.",
+		Long:  `Requests with a revoked key are answered with 401 after API_KEY_CACHE_TTL. Its usage is kept.`,
+		Args:  cobra.ExactArgs(1),
+		Run:   apiKeyRevokeCommand,
+	}
+
+	meiliSyncCmd = &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize the search indexes with the local database.",
+		Long:  `Adds, updates and deletes documents so the bonus and day indexes of every language match the database. Exits non-zero when an index fails.`,
+		Run:   meiliSyncCommand,
+	}
 )
 
 func migrateUp(cmd *cobra.Command, args []string) {
 	database := NewDatabaseRepository(context.Background(), ".")
 
-	dbDriver, err := sqlite3.WithInstance(database.Db, &sqlite3.Config{})
+	dbDriver, err := sqlite3.WithInstance(database.Writer, &sqlite3.Config{})
 	if err != nil {
 		log.Fatalf("instance error: %v \n", err)
 	}
@@ -95,7 +190,7 @@ func migrateUp(cmd *cobra.Command, args []string) {
 func migrateDown(cmd *cobra.Command, args []string) {
 	database := NewDatabaseRepository(context.Background(), ".")
 
-	dbDriver, err := sqlite3.WithInstance(database.Db, &sqlite3.Config{})
+	dbDriver, err := sqlite3.WithInstance(database.Writer, &sqlite3.Config{})
 	if err != nil {
 		log.Fatalf("instance error: %v \n", err)
 	}
@@ -117,6 +212,300 @@ func migrateDown(cmd *cobra.Command, args []string) {
 	log.Print("Migrate down done with success")
 }
 
+func generateStatic(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	outDir, err := cmd.Flags().GetString("out")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	manifest, err := GenerateStatic(database, outDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Info("Static API generated", "out", outDir, "first", manifest.FirstDate, "last", manifest.LastDate)
+}
+
+func checkCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	err = runCheck(context.Background(), database, format, os.Stdout)
+	if errors.Is(err, errCheckFailed) {
+		database.Deinit()
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func showCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lang, err := cmd.Flags().GetString("lang")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !sliceContains(Languages, lang) {
+		log.Fatal("unsupported language", "lang", lang, "supported", Languages)
+	}
+
+	bonusType, err := cmd.Flags().GetString("bonus")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	asJson, err := cmd.Flags().GetBool("json")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	asCsv, err := cmd.Flags().GetBool("csv")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	from, to, err := parseShowRange(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	var almanax []MappedAlmanax
+	if bonusType != "" {
+		almanax, err = database.GetAlmanaxByDateRangeAndNameID(context.Background(), from, to, bonusType)
+	} else {
+		almanax, err = database.GetAlmanaxByDateRange(context.Background(), from, to)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rendered := renderAlmanaxList(almanax, lang)
+	switch {
+	case asJson:
+		err = writeJson(os.Stdout, rendered)
+	case asCsv:
+		err = writeShowCsv(os.Stdout, rendered)
+	default:
+		err = writeShowTable(os.Stdout, rendered)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func tuiCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lang, err := cmd.Flags().GetString("lang")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	if err = RunTui(database, lang); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func exportCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lang, err := cmd.Flags().GetString("lang")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !sliceContains(Languages, lang) {
+		log.Fatal("unsupported language", "lang", lang, "supported", Languages)
+	}
+
+	from, err := cmd.Flags().GetString("from")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	to, err := cmd.Flags().GetString("to")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bonusType, err := cmd.Flags().GetString("bonus")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	outFile, err := cmd.Flags().GetString("out")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	err = exportAlmanaxFile(context.Background(), database, outFile, format, lang, from, to, bonusType)
+	database.Deinit()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func meiliSyncCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	search := NewSearchClient(MeiliHost, MeiliKey, MeiliTimeout)
+	defer search.Close()
+
+	results, err := SyncSearchIndexes(context.Background(), database, search.meili)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tADDED\tUPDATED\tDELETED\tUNCHANGED\tERROR")
+	failed := false
+	for _, result := range results {
+		errText := ""
+		if result.Err != nil {
+			errText = result.Err.Error()
+			failed = true
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", result.Index, result.Added, result.Updated, result.Deleted, result.Unchanged, errText)
+	}
+	tw.Flush()
+
+	if failed {
+		search.Close()
+		database.Deinit()
+		os.Exit(1)
+	}
+}
+
+func apiKeyCreateCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	perMinute, err := cmd.Flags().GetInt("per-minute")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	burst, err := cmd.Flags().GetInt("burst")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	key, apiKey, err := database.CreateApiKey(context.Background(), args[0], RateQuota{PerMinute: perMinute, Burst: burst})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Info("API key created", "id", apiKey.ID, "name", apiKey.Name, "per_minute", apiKey.RatePerMinute, "burst", apiKey.Burst)
+	fmt.Println(key)
+}
+
+func apiKeyListCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	apiKeys, err := database.ListApiKeys(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tPER MINUTE\tBURST\tREQUESTS\tLAST USED\tREVOKED")
+	for _, apiKey := range apiKeys {
+		lastUsed, revoked := "-", "-"
+		if apiKey.LastUsedAt != nil {
+			lastUsed = apiKey.LastUsedAt.Format(time.RFC3339)
+		}
+		if apiKey.RevokedAt != nil {
+			revoked = apiKey.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\t%s\t%s\n", apiKey.ID, apiKey.Name, apiKey.KeyPrefix, apiKey.RatePerMinute, apiKey.Burst,
+			apiKey.Requests, lastUsed, revoked)
+	}
+	tw.Flush()
+}
+
+func apiKeyRevokeCommand(cmd *cobra.Command, args []string) {
+	dbdir, err := cmd.Flags().GetString("dbdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		log.Fatal("invalid id", "id", args[0])
+	}
+
+	database := NewDatabaseRepository(context.Background(), dbdir)
+	defer database.Deinit()
+
+	revoked, err := database.RevokeApiKey(context.Background(), id)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !revoked {
+		database.Deinit()
+		log.Fatal("no active API key with this id", "id", id)
+	}
+
+	log.Info("API key revoked", "id", id)
+}
+
 func rootCommand(cmd *cobra.Command, args []string) {
 	if version, _ := cmd.Flags().GetBool("version"); version {
 		fmt.Println(DodudaVersion)
@@ -138,21 +527,51 @@ func rootCommand(cmd *cobra.Command, args []string) {
 		log.Fatal(err)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := setupTracing(ctx, TracingExporter, TracingOtlpEndpoint, TracingSampleRatio)
+	if err != nil {
+		log.Fatal("could not set up tracing", "err", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Warn("could not flush traces", "err", err)
+		}
+	}()
+
 	Database = NewDatabaseRepository(context.Background(), dbdir)
 	defer Database.Deinit()
 
-	almanaxData, err := loadAlmanaxData(gameVersion)
-	if err != nil {
+	Cache = NewAlmanaxCache(Database, CachePastDays, CacheFutureDays)
+
+	Search = NewSearchClient(MeiliHost, MeiliKey, MeiliTimeout)
+	defer Search.Close()
+
+	Limiter = NewRateLimiter()
+	ApiKeys = NewApiKeyStore(Database, ApiKeyCacheTtl)
+	Jobs.Go(ctx, "api key usage", func(ctx context.Context) error {
+		return ApiKeys.Run(ctx, time.Minute)
+	})
+
+	if _, err = importAlmanax(ctx, gameVersion); err != nil {
+		if ctx.Err() != nil {
+			log.Info("Startup import interrupted, shutting down")
+			Jobs.Shutdown(context.Background())
+			return
+		}
 		log.Fatal(err)
 	}
 
-	log.Info("Almanax data loaded", "count", len(almanaxData))
-
 	httpDataServer := &http.Server{
 		Addr:    fmt.Sprintf(":%s", ApiPort),
 		Handler: Router(),
 	}
 
+	serverErr := make(chan error, 2)
+
 	if metrics {
 		apiPort, _ := strconv.Atoi(ApiPort)
 		metricsPort := apiPort + 1
@@ -164,30 +583,100 @@ func rootCommand(cmd *cobra.Command, args []string) {
 		go func() {
 			log.Info("Metrics server started", "port", metricsPort)
 			if err := httpMetricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.Fatal(err)
+				serverErr <- err
 			}
 		}()
 	}
 
-	log.Info("Almanax server started", "port", ApiPort)
-	if err := httpDataServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatal(err)
+	go func() {
+		log.Info("Almanax server started", "port", ApiPort)
+		if err := httpDataServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		log.Info("Shutting down", "timeout", ShutdownTimeout)
+	case err := <-serverErr:
+		log.Error("server failed, shutting down", "err", err)
 	}
+	stop() // a second signal kills the process
+
+	shutdown(httpDataServer, httpMetricsServer, ShutdownTimeout)
+}
+
+// shutdown stops accepting requests, waits for in-flight requests and then cancels and waits for background
+// jobs, all within timeout. The database is closed by the caller afterwards.
+func shutdown(dataServer, metricsServer *http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := dataServer.Shutdown(ctx); err != nil {
+		log.Error("could not drain almanax server", "err", err)
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			log.Error("could not drain metrics server", "err", err)
+		}
+	}
+
+	if err := Jobs.Shutdown(ctx); err != nil {
+		log.Error("background jobs did not stop in time", "err", err)
+		return
+	}
+
+	log.Info("Shutdown complete")
 }
 
 func main() {
 	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "text")
 	viper.AutomaticEnv()
 
 	rootCmd.Flags().Bool("version", false, "Print the dodualm version.")
 	rootCmd.Flags().Bool("metrics", false, "Toggle Prometheus metrics export.")
-	rootCmd.Flags().String("dbdir", ".", "Database directory")
+	rootCmd.PersistentFlags().String("dbdir", ".", "Database directory")
 	rootCmd.Flags().String("game-version", "latest", "Specify the game version to use. Default is latest.")
 
 	rootCmd.AddCommand(migrateCmd)
 	migrateCmd.AddCommand(migrateDownCmd)
 	migrateCmd.AddCommand(migrateUpCmd)
 
+	generateStaticCmd.Flags().String("out", "static", "Output directory")
+	rootCmd.AddCommand(generateStaticCmd)
+
+	checkCmd.Flags().String("format", "text", "Output format, text or json")
+	rootCmd.AddCommand(checkCmd)
+
+	showCmd.Flags().String("lang", "en", "Language of the texts")
+	showCmd.Flags().String("bonus", "", "Only show days with this bonus type id")
+	showCmd.Flags().Bool("json", false, "Print json instead of a table")
+	showCmd.Flags().Bool("csv", false, "Print csv instead of a table")
+	showCmd.MarkFlagsMutuallyExclusive("json", "csv")
+	rootCmd.AddCommand(showCmd)
+
+	tuiCmd.Flags().String("lang", "en", "Initial language of the texts")
+	rootCmd.AddCommand(tuiCmd)
+
+	exportCmd.Flags().String("format", "csv", "Output format, csv or ndjson")
+	exportCmd.Flags().String("lang", "en", "Language of the texts")
+	exportCmd.Flags().String("from", "0000-01-01", "First date to export (yyyy-mm-dd)")
+	exportCmd.Flags().String("to", "9999-12-31", "Last date to export (yyyy-mm-dd)")
+	exportCmd.Flags().String("bonus", "", "Only export days with this bonus type id")
+	exportCmd.Flags().String("out", "", "Output file, default stdout")
+	rootCmd.AddCommand(exportCmd)
+
+	apiKeyCreateCmd.Flags().Int("per-minute", 600, "Requests per minute the key refills")
+	apiKeyCreateCmd.Flags().Int("burst", 100, "Requests the key can send at once")
+	apiKeyCmd.AddCommand(apiKeyCreateCmd)
+	apiKeyCmd.AddCommand(apiKeyListCmd)
+	apiKeyCmd.AddCommand(apiKeyRevokeCmd)
+	rootCmd.AddCommand(apiKeyCmd)
+
+	meiliCmd.AddCommand(meiliSyncCmd)
+	rootCmd.AddCommand(meiliCmd)
+
 	viper.SetDefault("MEILI_PORT", "7700")
 	viper.SetDefault("MEILI_MASTER_KEY", "masterKey")
 	viper.SetDefault("MEILI_PROTOCOL", "http")
@@ -196,6 +685,20 @@ func main() {
 	viper.SetDefault("API_SCHEME", "http")
 	viper.SetDefault("API_HOSTNAME", "localhost")
 	viper.SetDefault("SERVER_TZ", "Europe/Berlin")
+	viper.SetDefault("UPDATE_TOKEN", "")
+	viper.SetDefault("ADMIN_TOKENS", "")
+	viper.SetDefault("CACHE_PAST_DAYS", 7)
+	viper.SetDefault("CACHE_FUTURE_DAYS", 60)
+	viper.SetDefault("MEILI_TIMEOUT", "2s")
+	viper.SetDefault("SHUTDOWN_TIMEOUT", "15s")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("RATE_LIMIT_PER_MINUTE", 120)
+	viper.SetDefault("RATE_LIMIT_BURST", 30)
+	viper.SetDefault("API_KEY_CACHE_TTL", "1m")
+	viper.SetDefault("TRUST_PROXY_HEADERS", false)
+	viper.SetDefault("LANGUAGE_FALLBACKS", "pt:es,en;es:en;fr:en;de:en")
 
 	ApiScheme = viper.GetString("API_SCHEME")
 	ApiHostName = viper.GetString("API_HOSTNAME")
@@ -203,8 +706,32 @@ func main() {
 	MeiliKey = viper.GetString("MEILI_MASTER_KEY")
 	MeiliHost = fmt.Sprintf("%s://%s:%s", viper.GetString("MEILI_PROTOCOL"), viper.GetString("MEILI_HOST"), viper.GetString("MEILI_PORT"))
 	ServerTz = getEnv("SERVER_TZ", "Europe/Berlin")
+	UpdateToken = viper.GetString("UPDATE_TOKEN")
+	CachePastDays = viper.GetInt("CACHE_PAST_DAYS")
+	CacheFutureDays = viper.GetInt("CACHE_FUTURE_DAYS")
+	MeiliTimeout = viper.GetDuration("MEILI_TIMEOUT")
+	ShutdownTimeout = viper.GetDuration("SHUTDOWN_TIMEOUT")
+	TracingExporter = viper.GetString("TRACING_EXPORTER")
+	TracingOtlpEndpoint = viper.GetString("TRACING_OTLP_ENDPOINT")
+	TracingSampleRatio = viper.GetFloat64("TRACING_SAMPLE_RATIO")
+	DefaultRateQuota = RateQuota{PerMinute: viper.GetInt("RATE_LIMIT_PER_MINUTE"), Burst: viper.GetInt("RATE_LIMIT_BURST")}
+	ApiKeyCacheTtl = viper.GetDuration("API_KEY_CACHE_TTL")
+	TrustProxyHeaders = viper.GetBool("TRUST_PROXY_HEADERS")
+
+	var err error
+	if err = configureLogging(viper.GetString("LOG_LEVEL"), viper.GetString("LOG_FORMAT")); err != nil {
+		log.Fatal("invalid logging configuration", "err", err)
+	}
+
+	if AdminTokens, err = parseAdminTokens(viper.GetString("ADMIN_TOKENS")); err != nil {
+		log.Fatal("invalid ADMIN_TOKENS", "err", err)
+	}
+
+	if LanguageFallbacks, err = parseLanguageFallbacks(viper.GetString("LANGUAGE_FALLBACKS")); err != nil {
+		log.Fatal("invalid LANGUAGE_FALLBACKS", "err", err)
+	}
 
-	err := rootCmd.Execute()
+	err = rootCmd.Execute()
 	if err != nil && err.Error() != "" {
 		fmt.Fprintln(os.Stderr, err)
 	}
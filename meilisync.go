@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/meilisearch/meilisearch-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// syncMu serializes index synchronizations, an import can finish while a manual sync is running.
+var syncMu sync.Mutex
+
+type SearchSyncResult struct {
+	Index     string `json:"index"`
+	Lang      string `json:"lang"`
+	Added     int    `json:"added"`
+	Updated   int    `json:"updated"`
+	Deleted   int    `json:"deleted"`
+	Unchanged int    `json:"unchanged"`
+	Err       error  `json:"-"`
+}
+
+func bonusIndexName(lang string) string {
+	return fmt.Sprintf("alm-bonuses-%s", lang)
+}
+
+func dayIndexName(lang string) string {
+	return fmt.Sprintf("alm-days-%s", lang)
+}
+
+// searchDocument is a document of one of the search indexes, identified by its primary key.
+type searchDocument interface {
+	DocumentId() string
+}
+
+func (d AlmanaxBonusListingMeili) DocumentId() string {
+	return d.Id
+}
+
+func (d AlmanaxDayMeili) DocumentId() string {
+	return d.Id
+}
+
+// dayIndexSettings sorts by date before relevancy, so search results read like a calendar.
+var dayIndexSettings = &meilisearch.Settings{
+	RankingRules:         []string{"sort", "words", "typo", "proximity", "attribute", "exactness"},
+	SearchableAttributes: []string{"tribute_item_name", "bonus_type_name", "bonus_description"},
+	FilterableAttributes: []string{"bonus_type_id", "item_subtype", "timestamp"},
+	SortableAttributes:   []string{"timestamp"},
+}
+
+// meiliDocumentId maps a name id to the characters Meilisearch allows in ids. It is deterministic, so
+// the same bonus type always replaces its own document.
+func meiliDocumentId(nameID string) string {
+	var sb strings.Builder
+	for _, c := range nameID {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' {
+			sb.WriteRune(c)
+		} else {
+			fmt.Fprintf(&sb, "_%x", c)
+		}
+	}
+	return sb.String()
+}
+
+func bonusDocuments(bonusTypes []BonusType, lang string) []AlmanaxBonusListingMeili {
+	docs := make([]AlmanaxBonusListingMeili, 0, len(bonusTypes))
+	for _, listing := range renderBonusListing(bonusTypes, lang) {
+		docs = append(docs, AlmanaxBonusListingMeili{
+			Id:        meiliDocumentId(listing.Id),
+			Slug:      listing.Id,
+			Name:      listing.Name,
+			Fallbacks: listing.Fallbacks,
+		})
+	}
+	return docs
+}
+
+func dayDocuments(almanax []MappedAlmanax, lang string) []AlmanaxDayMeili {
+	docs := make([]AlmanaxDayMeili, 0, len(almanax))
+	for i := range almanax {
+		alm := renderAlmanax(&almanax[i], lang)
+		date, err := time.Parse(DateLayout, alm.Date)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, AlmanaxDayMeili{
+			Id:               alm.Date,
+			Date:             alm.Date,
+			Timestamp:        date.Unix(),
+			BonusTypeId:      alm.Bonus.Type.Id,
+			BonusTypeName:    alm.Bonus.Type.Name,
+			BonusDescription: alm.Bonus.Description,
+			TributeItemName:  alm.Tribute.Item.Name,
+			ItemSubtype:      alm.Tribute.Item.Subtype,
+		})
+	}
+	return docs
+}
+
+// diffDocuments returns the documents to add or replace and the ids to delete so the index matches want.
+func diffDocuments[T searchDocument](want []T, have map[string]T, result *SearchSyncResult) ([]T, []string) {
+	var upserts []T
+	wanted := make(map[string]bool, len(want))
+	for _, doc := range want {
+		wanted[doc.DocumentId()] = true
+
+		existing, ok := have[doc.DocumentId()]
+		switch {
+		case !ok:
+			result.Added++
+			upserts = append(upserts, doc)
+		case !reflect.DeepEqual(existing, doc):
+			result.Updated++
+			upserts = append(upserts, doc)
+		default:
+			result.Unchanged++
+		}
+	}
+
+	var deletes []string
+	for id := range have {
+		if !wanted[id] {
+			deletes = append(deletes, id)
+		}
+	}
+	result.Deleted = len(deletes)
+
+	return upserts, deletes
+}
+
+func waitForMeiliTask(ctx context.Context, client meilisearch.ServiceManager, task *meilisearch.TaskInfo) error {
+	finished, err := client.WaitForTaskWithContext(ctx, task.TaskUID, 100*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	if finished.Status == meilisearch.TaskStatusFailed {
+		return fmt.Errorf("meili task %d failed: %s", task.TaskUID, finished.Error.Message)
+	}
+	return nil
+}
+
+// ensureMeiliIndex creates the index if it is missing and applies the settings, which is a no-op for Meilisearch
+// when nothing changed.
+func ensureMeiliIndex(ctx context.Context, client meilisearch.ServiceManager, uid string, settings *meilisearch.Settings) error {
+	_, err := client.GetIndexWithContext(ctx, uid)
+	var meiliErr *meilisearch.Error
+	if err != nil && errors.As(err, &meiliErr) && meiliErr.StatusCode == 404 {
+		log.FromContext(ctx).Info("search index does not exist yet, creating now", "index", uid)
+		var task *meilisearch.TaskInfo
+		if task, err = client.CreateIndexWithContext(ctx, &meilisearch.IndexConfig{Uid: uid, PrimaryKey: "id"}); err == nil {
+			err = waitForMeiliTask(ctx, client, task)
+		}
+	}
+	if err != nil || settings == nil {
+		return err
+	}
+
+	task, err := client.Index(uid).UpdateSettingsWithContext(ctx, settings)
+	if err != nil {
+		return err
+	}
+	return waitForMeiliTask(ctx, client, task)
+}
+
+func getIndexDocuments[T searchDocument](ctx context.Context, index meilisearch.IndexManager) (map[string]T, error) {
+	docs := make(map[string]T)
+	query := &meilisearch.DocumentsQuery{Limit: 1000}
+	for {
+		var res meilisearch.DocumentsResult
+		if err := index.GetDocumentsWithContext(ctx, query, &res); err != nil {
+			return nil, err
+		}
+
+		for _, raw := range res.Results {
+			encoded, err := json.Marshal(raw)
+			if err != nil {
+				return nil, err
+			}
+			var doc T
+			if err = json.Unmarshal(encoded, &doc); err != nil {
+				return nil, err
+			}
+			docs[doc.DocumentId()] = doc
+		}
+
+		query.Offset += int64(len(res.Results))
+		if len(res.Results) == 0 || query.Offset >= res.Total {
+			return docs, nil
+		}
+	}
+}
+
+func syncIndex[T searchDocument](ctx context.Context, client meilisearch.ServiceManager, uid, lang string, settings *meilisearch.Settings, want []T) (result SearchSyncResult) {
+	ctx, span := tracer.Start(ctx, "sync search index", trace.WithAttributes(attribute.String("dodualm.search.index", uid)))
+	defer func() {
+		span.SetAttributes(attribute.Int("dodualm.search.added", result.Added), attribute.Int("dodualm.search.updated", result.Updated),
+			attribute.Int("dodualm.search.deleted", result.Deleted))
+		endSpan(span, result.Err)
+	}()
+
+	result = SearchSyncResult{Index: uid, Lang: lang}
+
+	if result.Err = ensureMeiliIndex(ctx, client, uid, settings); result.Err != nil {
+		return result
+	}
+
+	index := client.Index(uid)
+	have, err := getIndexDocuments[T](ctx, index)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	upserts, deletes := diffDocuments(want, have, &result)
+
+	if len(upserts) > 0 {
+		task, err := index.AddDocumentsWithContext(ctx, upserts, "id")
+		if err == nil {
+			err = waitForMeiliTask(ctx, client, task)
+		}
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	if len(deletes) > 0 {
+		task, err := index.DeleteDocumentsWithContext(ctx, deletes)
+		if err == nil {
+			err = waitForMeiliTask(ctx, client, task)
+		}
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	return result
+}
+
+// SyncSearchIndexes makes the bonus and day indexes of every language match the database.
+// Indexes are synchronized independently, a failing one does not stop the others.
+func SyncSearchIndexes(ctx context.Context, repo *Repository, client meilisearch.ServiceManager) (_ []SearchSyncResult, err error) {
+	ctx, span := tracer.Start(ctx, "sync search indexes")
+	defer func() { endSpan(span, err) }()
+
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	logger := log.FromContext(ctx)
+
+	bonusTypes, err := repo.GetBonusTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	almanax, err := repo.GetAlmanaxByDateRange(ctx, "0000-01-01", "9999-12-31")
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchSyncResult, 0, 2*len(Languages))
+	for _, lang := range Languages {
+		results = append(results,
+			syncIndex(ctx, client, bonusIndexName(lang), lang, nil, bonusDocuments(bonusTypes, lang)),
+			syncIndex(ctx, client, dayIndexName(lang), lang, dayIndexSettings, dayDocuments(almanax, lang)))
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			logger.Error("search index sync failed", "index", result.Index, "err", result.Err)
+		} else {
+			logger.Info("search index synced", "index", result.Index, "added", result.Added, "updated", result.Updated, "deleted", result.Deleted, "unchanged", result.Unchanged)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeiliDocumentId(t *testing.T) {
+	assert.Equal(t, "harvest-time", meiliDocumentId("harvest-time"))
+	assert.Equal(t, "r_e9colte", meiliDocumentId("récolte"))
+}
+
+func TestDiffDocuments(t *testing.T) {
+	want := []AlmanaxBonusListingMeili{
+		{Id: "harvest", Slug: "harvest", Name: "Harvest"},
+		{Id: "fishing", Slug: "fishing", Name: "Fishing"},
+		{Id: "wisdom", Slug: "wisdom", Name: "Sabedoria", Fallbacks: map[string]string{"name": "es"}},
+	}
+	have := map[string]AlmanaxBonusListingMeili{
+		"harvest": {Id: "harvest", Slug: "harvest", Name: "Harvest"},
+		"fishing": {Id: "fishing", Slug: "fishing", Name: "Fish"},
+		"wisdom":  {Id: "wisdom", Slug: "wisdom", Name: "Sabedoria"},
+		"0":       {Id: "0", Slug: "harvest", Name: "Harvest"},
+	}
+
+	var result SearchSyncResult
+	upserts, deletes := diffDocuments(want, have, &result)
+	assert.Equal(t, want[1:], upserts)
+	assert.Equal(t, []string{"0"}, deletes)
+	assert.Equal(t, SearchSyncResult{Updated: 2, Deleted: 1, Unchanged: 1}, result)
+
+	result = SearchSyncResult{}
+	upserts, deletes = diffDocuments(want, nil, &result)
+	assert.Len(t, upserts, 3)
+	assert.Empty(t, deletes)
+	assert.Equal(t, 3, result.Added)
+}
+
+func TestMeiliFilterValue(t *testing.T) {
+	assert.Equal(t, `"resource"`, meiliFilterValue("resource"))
+	assert.Equal(t, `"a \"b\" \\c"`, meiliFilterValue(`a "b" \c`))
+}
@@ -1,13 +1,239 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	requestsTotal = promauto.NewCounter(prometheus.CounterOpts{
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dodualm_http_requests_total",
+		Help: "The total number of HTTP requests by route pattern, method, status and response language.",
+	}, []string{"route", "method", "status", "lang"})
+
+	// legacyRequestsTotal keeps the name of the first request counter, so existing scrapes and alerts keep working.
+	legacyRequestsTotal = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "dodualm_requestsTotal",
-		Help: "The total number of CRUD requests for all types.",
+		Help: "Deprecated, use dodualm_http_requests_total. The total number of HTTP requests.",
+	})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dodualm_http_request_duration_seconds",
+		Help:    "The HTTP request latency by route pattern, method and response language.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "lang"})
+
+	importDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dodualm_import_duration_seconds",
+		Help:    "The duration of almanax imports including the download by outcome, success or failure.",
+		Buckets: []float64{1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"outcome"})
+
+	importDaysTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dodualm_import_days_total",
+		Help: "The total number of imported days by result, inserted, updated, unchanged, kept or skipped_past.",
+	}, []string{"result"})
+
+	importLastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dodualm_import_last_success_timestamp_seconds",
+		Help: "The time of the last successful import as unix timestamp.",
+	})
+
+	datasetInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dodualm_dataset_info",
+		Help: "The dataset tag that is part of every ETag, always 1.",
+	}, []string{"dataset_tag"})
+
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dodualm_db_query_duration_seconds",
+		Help:    "The duration of database queries by query name.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"query"})
+
+	coverageFirstDate = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dodualm_almanax_first_date_timestamp_seconds",
+		Help: "The first date with almanax data as unix timestamp.",
+	})
+
+	coverageLastDate = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dodualm_almanax_last_date_timestamp_seconds",
+		Help: "The last date with almanax data as unix timestamp.",
+	})
+
+	coverageFutureDays = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dodualm_almanax_future_days",
+		Help: "The number of days from today until the last date with almanax data, as of the latest import or edit.",
+	})
+
+	coverageMissingDays = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dodualm_almanax_missing_days",
+		Help: "The number of days without almanax data between the first and the last date.",
+	})
+
+	coverageImportedAt = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dodualm_almanax_imported_at_timestamp_seconds",
+		Help: "The time of the import that produced the current future data as unix timestamp.",
+	})
+
+	coverageRelease = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dodualm_almanax_release_info",
+		Help: "The dofus3-main release that produced the current future data, always 1.",
+	}, []string{"release_tag"})
+
+	cacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dodualm_almanax_cache_hits_total",
+		Help: "The total number of almanax range lookups served from the cache.",
+	})
+
+	cacheMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dodualm_almanax_cache_misses_total",
+		Help: "The total number of almanax range lookups inside the cache window that went to the database.",
+	})
+
+	searchBreakerState = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dodualm_search_breaker_state",
+		Help: "The state of the Meilisearch circuit breaker, 0 closed, 1 half-open, 2 open.",
+	})
+
+	searchCacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dodualm_search_cache_hits_total",
+		Help: "The total number of searches answered from fresh cached results.",
+	})
+
+	searchCacheMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dodualm_search_cache_misses_total",
+		Help: "The total number of searches that had to ask Meilisearch.",
+	})
+
+	meiliRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dodualm_meili_request_duration_seconds",
+		Help:    "The latency of Meilisearch calls by operation.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation"})
+
+	meiliErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dodualm_meili_errors_total",
+		Help: "The total number of Meilisearch calls by operation that failed or answered with a server error.",
+	}, []string{"operation"})
+
+	rateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dodualm_rate_limited_requests_total",
+		Help: "The total number of requests answered with 429 by client kind, ip or key.",
+	}, []string{"client"})
+
+	searchStaleTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dodualm_search_stale_responses_total",
+		Help: "The total number of search responses served from stale results because Meilisearch was unavailable.",
 	})
 )
+
+// metricsMiddleware counts and times requests by route pattern, so path parameters do not blow up the label set.
+// The language is taken from Content-Language, which the language middlewares set for both route variants.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		lang := ww.Header().Get("Content-Language")
+		if lang == "" {
+			lang = "none"
+		}
+
+		requestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(status), lang).Inc()
+		legacyRequestsTotal.Inc()
+		requestDuration.WithLabelValues(route, r.Method, lang).Observe(time.Since(start).Seconds())
+	})
+}
+
+// meiliOperation maps a Meilisearch API path to a small set of operation names.
+func meiliOperation(path string) string {
+	switch {
+	case strings.HasSuffix(path, "/search"):
+		return "search"
+	case strings.Contains(path, "/documents"):
+		return "documents"
+	case strings.HasSuffix(path, "/settings"):
+		return "settings"
+	case strings.HasPrefix(path, "/tasks"):
+		return "tasks"
+	case strings.HasPrefix(path, "/indexes"):
+		return "indexes"
+	case path == "/health":
+		return "health"
+	}
+	return "other"
+}
+
+// meiliTransport observes and traces every call the Meilisearch client makes, searches as well as index syncs.
+type meiliTransport struct {
+	next http.RoundTripper
+}
+
+func (t meiliTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation := meiliOperation(req.URL.Path)
+	_, span := tracer.Start(req.Context(), "meili "+operation, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(req.Method), semconv.URLPath(req.URL.Path)))
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	meiliRequestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil || res.StatusCode >= 500 {
+		meiliErrorsTotal.WithLabelValues(operation).Inc()
+	}
+
+	if res != nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+		if res.StatusCode >= 500 {
+			span.SetStatus(codes.Error, res.Status)
+		}
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+// observeImport records the outcome of an import started at start.
+func observeImport(start time.Time, result *ImportResult, err error) {
+	if err != nil {
+		importDuration.WithLabelValues("failure").Observe(time.Since(start).Seconds())
+		return
+	}
+	importDuration.WithLabelValues("success").Observe(time.Since(start).Seconds())
+	importDaysTotal.WithLabelValues("inserted").Add(float64(result.Inserted))
+	importDaysTotal.WithLabelValues("updated").Add(float64(result.Updated))
+	importDaysTotal.WithLabelValues("unchanged").Add(float64(result.Unchanged))
+	importDaysTotal.WithLabelValues("kept").Add(float64(result.Kept))
+	importDaysTotal.WithLabelValues("skipped_past").Add(float64(result.SkippedPast))
+	importLastSuccess.SetToCurrentTime()
+}
+
+// observeQuery times a database query and traces it as a child of ctx, call the returned function when it finished.
+func observeQuery(ctx context.Context, name string) func() {
+	timer := prometheus.NewTimer(dbQueryDuration.WithLabelValues(name))
+	_, span := tracer.Start(ctx, "db "+name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemSqlite, attribute.String("db.operation.name", name)))
+	return func() {
+		timer.ObserveDuration()
+		span.End()
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(metricsMiddleware)
+	r.With(languageChecker).Get("/metrics-test/{lang}/days", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	legacy := testutil.ToFloat64(legacyRequestsTotal)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics-test/fr/days", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics-test/xx/days", nil))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(requestsTotal.WithLabelValues("/metrics-test/{lang}/days", "GET", "204", "fr")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(requestsTotal.WithLabelValues("/metrics-test/{lang}/days", "GET", "400", "none")))
+	assert.Equal(t, legacy+2, testutil.ToFloat64(legacyRequestsTotal))
+}
+
+func TestMeiliOperation(t *testing.T) {
+	assert.Equal(t, "search", meiliOperation("/indexes/alm-days-en/search"))
+	assert.Equal(t, "documents", meiliOperation("/indexes/alm-days-en/documents/delete-batch"))
+	assert.Equal(t, "settings", meiliOperation("/indexes/alm-days-en/settings"))
+	assert.Equal(t, "tasks", meiliOperation("/tasks/12"))
+	assert.Equal(t, "indexes", meiliOperation("/indexes/alm-days-en"))
+}
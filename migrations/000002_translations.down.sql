@@ -0,0 +1,43 @@
+alter table bonus_types add column name_en text;
+alter table bonus_types add column name_fr text;
+alter table bonus_types add column name_es text;
+alter table bonus_types add column name_de text;
+alter table bonus_types add column name_it text;
+alter table bonus_types add column name_pt text;
+
+update bonus_types set name_en = (select value from translations where entity = 'bonus_types' and entity_id = bonus_types.id and lang = 'en');
+update bonus_types set name_fr = (select value from translations where entity = 'bonus_types' and entity_id = bonus_types.id and lang = 'fr');
+update bonus_types set name_es = (select value from translations where entity = 'bonus_types' and entity_id = bonus_types.id and lang = 'es');
+update bonus_types set name_de = (select value from translations where entity = 'bonus_types' and entity_id = bonus_types.id and lang = 'de');
+update bonus_types set name_it = (select value from translations where entity = 'bonus_types' and entity_id = bonus_types.id and lang = 'it');
+update bonus_types set name_pt = (select value from translations where entity = 'bonus_types' and entity_id = bonus_types.id and lang = 'pt');
+
+alter table bonus add column description_en text;
+alter table bonus add column description_fr text;
+alter table bonus add column description_es text;
+alter table bonus add column description_de text;
+alter table bonus add column description_it text;
+alter table bonus add column description_pt text;
+
+update bonus set description_en = (select value from translations where entity = 'bonus' and entity_id = bonus.id and lang = 'en');
+update bonus set description_fr = (select value from translations where entity = 'bonus' and entity_id = bonus.id and lang = 'fr');
+update bonus set description_es = (select value from translations where entity = 'bonus' and entity_id = bonus.id and lang = 'es');
+update bonus set description_de = (select value from translations where entity = 'bonus' and entity_id = bonus.id and lang = 'de');
+update bonus set description_it = (select value from translations where entity = 'bonus' and entity_id = bonus.id and lang = 'it');
+update bonus set description_pt = (select value from translations where entity = 'bonus' and entity_id = bonus.id and lang = 'pt');
+
+alter table tribute add column item_name_en text;
+alter table tribute add column item_name_fr text;
+alter table tribute add column item_name_es text;
+alter table tribute add column item_name_de text;
+alter table tribute add column item_name_it text;
+alter table tribute add column item_name_pt text;
+
+update tribute set item_name_en = (select value from translations where entity = 'tribute' and entity_id = tribute.id and lang = 'en');
+update tribute set item_name_fr = (select value from translations where entity = 'tribute' and entity_id = tribute.id and lang = 'fr');
+update tribute set item_name_es = (select value from translations where entity = 'tribute' and entity_id = tribute.id and lang = 'es');
+update tribute set item_name_de = (select value from translations where entity = 'tribute' and entity_id = tribute.id and lang = 'de');
+update tribute set item_name_it = (select value from translations where entity = 'tribute' and entity_id = tribute.id and lang = 'it');
+update tribute set item_name_pt = (select value from translations where entity = 'tribute' and entity_id = tribute.id and lang = 'pt');
+
+drop table if exists translations;
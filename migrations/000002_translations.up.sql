@@ -0,0 +1,70 @@
+create table translations (
+    entity text not null,
+    /* bonus_types, bonus or tribute */
+    entity_id integer not null,
+    lang text not null,
+    value text not null,
+    created_at datetime default current_timestamp,
+    updated_at datetime default current_timestamp,
+    primary key (entity, entity_id, lang)
+);
+
+insert into translations (entity, entity_id, lang, value)
+select 'bonus_types', id, 'en', name_en from bonus_types where name_en is not null and name_en != ''
+union all
+select 'bonus_types', id, 'fr', name_fr from bonus_types where name_fr is not null and name_fr != ''
+union all
+select 'bonus_types', id, 'es', name_es from bonus_types where name_es is not null and name_es != ''
+union all
+select 'bonus_types', id, 'de', name_de from bonus_types where name_de is not null and name_de != ''
+union all
+select 'bonus_types', id, 'it', name_it from bonus_types where name_it is not null and name_it != ''
+union all
+select 'bonus_types', id, 'pt', name_pt from bonus_types where name_pt is not null and name_pt != '';
+
+insert into translations (entity, entity_id, lang, value)
+select 'bonus', id, 'en', description_en from bonus where description_en is not null and description_en != ''
+union all
+select 'bonus', id, 'fr', description_fr from bonus where description_fr is not null and description_fr != ''
+union all
+select 'bonus', id, 'es', description_es from bonus where description_es is not null and description_es != ''
+union all
+select 'bonus', id, 'de', description_de from bonus where description_de is not null and description_de != ''
+union all
+select 'bonus', id, 'it', description_it from bonus where description_it is not null and description_it != ''
+union all
+select 'bonus', id, 'pt', description_pt from bonus where description_pt is not null and description_pt != '';
+
+insert into translations (entity, entity_id, lang, value)
+select 'tribute', id, 'en', item_name_en from tribute where item_name_en is not null and item_name_en != ''
+union all
+select 'tribute', id, 'fr', item_name_fr from tribute where item_name_fr is not null and item_name_fr != ''
+union all
+select 'tribute', id, 'es', item_name_es from tribute where item_name_es is not null and item_name_es != ''
+union all
+select 'tribute', id, 'de', item_name_de from tribute where item_name_de is not null and item_name_de != ''
+union all
+select 'tribute', id, 'it', item_name_it from tribute where item_name_it is not null and item_name_it != ''
+union all
+select 'tribute', id, 'pt', item_name_pt from tribute where item_name_pt is not null and item_name_pt != '';
+
+alter table bonus_types drop column name_en;
+alter table bonus_types drop column name_fr;
+alter table bonus_types drop column name_es;
+alter table bonus_types drop column name_de;
+alter table bonus_types drop column name_it;
+alter table bonus_types drop column name_pt;
+
+alter table bonus drop column description_en;
+alter table bonus drop column description_fr;
+alter table bonus drop column description_es;
+alter table bonus drop column description_de;
+alter table bonus drop column description_it;
+alter table bonus drop column description_pt;
+
+alter table tribute drop column item_name_en;
+alter table tribute drop column item_name_fr;
+alter table tribute drop column item_name_es;
+alter table tribute drop column item_name_de;
+alter table tribute drop column item_name_it;
+alter table tribute drop column item_name_pt;
@@ -0,0 +1,3 @@
+drop index if exists idx_imports_imported_at;
+
+drop table if exists imports;
@@ -0,0 +1,10 @@
+create table imports (
+    id integer primary key autoincrement,
+    release_tag text not null,
+    inserted integer not null,
+    updated integer not null,
+    unchanged integer not null,
+    imported_at datetime default current_timestamp
+);
+
+create index idx_imports_imported_at on imports (imported_at);
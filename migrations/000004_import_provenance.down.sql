@@ -0,0 +1,5 @@
+alter table imports drop column dodualm_version;
+alter table imports drop column received;
+alter table imports drop column downloaded_at;
+alter table imports drop column asset_id;
+alter table imports drop column requested_version;
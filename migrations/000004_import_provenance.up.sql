@@ -0,0 +1,5 @@
+alter table imports add column requested_version text not null default '';
+alter table imports add column asset_id integer;
+alter table imports add column downloaded_at datetime;
+alter table imports add column received integer not null default 0;
+alter table imports add column dodualm_version text not null default '';
@@ -0,0 +1,2 @@
+drop table api_key_usage;
+drop table api_keys;
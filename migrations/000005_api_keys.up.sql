@@ -0,0 +1,18 @@
+create table api_keys (
+    id integer primary key autoincrement,
+    name text not null,
+    key_hash text not null unique,
+    key_prefix text not null,
+    rate_per_minute integer not null,
+    burst integer not null,
+    created_at datetime default current_timestamp,
+    last_used_at datetime,
+    revoked_at datetime
+);
+
+create table api_key_usage (
+    api_key_id integer not null references api_keys(id) on delete cascade,
+    day text not null,
+    requests integer not null default 0,
+    primary key (api_key_id, day)
+);
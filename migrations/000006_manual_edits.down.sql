@@ -0,0 +1,9 @@
+alter table imports drop column kept;
+alter table tribute drop column edited_at;
+alter table tribute drop column edited_by;
+alter table bonus_types drop column edited_at;
+alter table bonus_types drop column edited_by;
+alter table bonus drop column edited_at;
+alter table bonus drop column edited_by;
+alter table almanax drop column edited_at;
+alter table almanax drop column edited_by;
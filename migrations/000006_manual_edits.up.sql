@@ -0,0 +1,9 @@
+alter table almanax add column edited_by text not null default '';
+alter table almanax add column edited_at datetime;
+alter table bonus add column edited_by text not null default '';
+alter table bonus add column edited_at datetime;
+alter table bonus_types add column edited_by text not null default '';
+alter table bonus_types add column edited_at datetime;
+alter table tribute add column edited_by text not null default '';
+alter table tribute add column edited_at datetime;
+alter table imports add column kept integer not null default 0;
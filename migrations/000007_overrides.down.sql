@@ -0,0 +1,4 @@
+alter table imports drop column overrides_disagreed;
+alter table imports drop column overrides_agreed;
+drop index idx_overrides_date;
+drop table overrides;
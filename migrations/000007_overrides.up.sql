@@ -0,0 +1,23 @@
+create table overrides (
+    id integer primary key autoincrement,
+    date text not null,
+    bonus_id integer,
+    tribute_id integer,
+    reason text not null,
+    expires_at datetime not null,
+    /* what the latest import said about the date, null until an import saw it */
+    release_tag text,
+    release_agrees integer,
+    edited_by text not null default '',
+    edited_at datetime,
+    created_at datetime default current_timestamp,
+    updated_at datetime default current_timestamp,
+    deleted_at datetime,
+    foreign key (bonus_id) references bonus (id),
+    foreign key (tribute_id) references tribute (id)
+);
+
+create unique index idx_overrides_date on overrides (date) where deleted_at is null;
+
+alter table imports add column overrides_agreed integer not null default 0;
+alter table imports add column overrides_disagreed integer not null default 0;
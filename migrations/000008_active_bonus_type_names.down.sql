@@ -0,0 +1,2 @@
+drop index idx_bonus_types_name_id;
+create unique index idx_bonus_types_name_id on bonus_types (name_id);
@@ -0,0 +1,3 @@
+-- a soft-deleted bonus type must not block an import from creating the type again
+drop index idx_bonus_types_name_id;
+create unique index idx_bonus_types_name_id on bonus_types (name_id) where deleted_at is null;
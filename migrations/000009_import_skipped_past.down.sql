@@ -0,0 +1 @@
+alter table imports drop column skipped_past;
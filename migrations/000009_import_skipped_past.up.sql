@@ -0,0 +1 @@
+alter table imports add column skipped_past integer not null default 0;
@@ -0,0 +1,661 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The OpenAPI document is built from Go values, response schemas are reflected from the response types,
+// so the json tags stay the single source of truth. TestOpenApiCoversRoutes fails for undocumented routes.
+
+type OpenApiDocument struct {
+	OpenApi    string                     `json:"openapi"`
+	Info       OpenApiInfo                `json:"info"`
+	Servers    []OpenApiServer            `json:"servers"`
+	Paths      map[string]OpenApiPathItem `json:"paths"`
+	Components OpenApiComponents          `json:"components"`
+}
+
+type OpenApiInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type OpenApiServer struct {
+	Url string `json:"url"`
+}
+
+// OpenApiPathItem maps lower case http methods to operations.
+type OpenApiPathItem map[string]*OpenApiOperation
+
+type OpenApiOperation struct {
+	OperationId string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenApiParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenApiRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenApiResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type OpenApiParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenApiSchema `json:"schema"`
+}
+
+type OpenApiRequestBody struct {
+	Required bool                        `json:"required,omitempty"`
+	Content  map[string]OpenApiMediaType `json:"content"`
+}
+
+type OpenApiResponse struct {
+	Description string                      `json:"description"`
+	Headers     map[string]OpenApiHeader    `json:"headers,omitempty"`
+	Content     map[string]OpenApiMediaType `json:"content,omitempty"`
+}
+
+type OpenApiHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *OpenApiSchema `json:"schema"`
+}
+
+type OpenApiMediaType struct {
+	Schema *OpenApiSchema `json:"schema"`
+}
+
+type OpenApiSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Default              any                       `json:"default,omitempty"`
+	Minimum              *int                      `json:"minimum,omitempty"`
+	Maximum              *int                      `json:"maximum,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Items                *OpenApiSchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenApiSchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AdditionalProperties *OpenApiSchema            `json:"additionalProperties,omitempty"`
+}
+
+type OpenApiSecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenApiComponents struct {
+	Schemas         map[string]*OpenApiSchema        `json:"schemas"`
+	SecuritySchemes map[string]OpenApiSecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type openApiBuilder struct {
+	doc *OpenApiDocument
+}
+
+// schemaName turns ApiPage[...AlmanaxResponse] into AlmanaxResponsePage.
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if open := strings.Index(name, "["); open != -1 {
+		inner := strings.TrimSuffix(name[open+1:], "]")
+		inner = inner[strings.LastIndex(inner, ".")+1:]
+		return inner + strings.TrimPrefix(name[:open], "Api")
+	}
+	return name
+}
+
+// schema reflects the json encoding of v. Named structs are added to the components and referenced.
+func (b *openApiBuilder) schema(v any) *OpenApiSchema {
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+func (b *openApiBuilder) schemaOf(t reflect.Type) *OpenApiSchema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &OpenApiSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.schemaOf(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &OpenApiSchema{Type: "string"}
+	case reflect.Bool:
+		return &OpenApiSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Uint, reflect.Uint32:
+		return &OpenApiSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenApiSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenApiSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &OpenApiSchema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &OpenApiSchema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, ok := b.doc.Components.Schemas[name]; !ok {
+			b.doc.Components.Schemas[name] = nil // reserve the name for recursive types
+			b.doc.Components.Schemas[name] = b.structSchema(t)
+		}
+		return &OpenApiSchema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &OpenApiSchema{}
+}
+
+func (b *openApiBuilder) structSchema(t reflect.Type) *OpenApiSchema {
+	s := &OpenApiSchema{Type: "object", Properties: make(map[string]*OpenApiSchema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct { // promoted fields
+			embedded := b.structSchema(field.Type)
+			for name, prop := range embedded.Properties {
+				s.Properties[name] = prop
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = b.schemaOf(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+func jsonContent(schema *OpenApiSchema) map[string]OpenApiMediaType {
+	return map[string]OpenApiMediaType{"application/json": {Schema: schema}}
+}
+
+func (b *openApiBuilder) jsonResponse(description string, v any) OpenApiResponse {
+	return OpenApiResponse{Description: description, Content: jsonContent(b.schema(v))}
+}
+
+func (b *openApiBuilder) errorResponse(description string) OpenApiResponse {
+	return b.jsonResponse(description, ApiError{})
+}
+
+// cachedResponse documents the headers written by WriteCacheHeader.
+func (b *openApiBuilder) cachedResponse(description string, content map[string]OpenApiMediaType) OpenApiResponse {
+	return OpenApiResponse{
+		Description: description,
+		Content:     content,
+		Headers: map[string]OpenApiHeader{
+			"Cache-Control": {Schema: &OpenApiSchema{Type: "string"}},
+			"ETag":          {Schema: &OpenApiSchema{Type: "string"}},
+			"Last-Modified": {Schema: &OpenApiSchema{Type: "string"}},
+			ReleaseHeader:   {Description: "dofus3-main release of the data, sent by the almanax and bonus endpoints.", Schema: &OpenApiSchema{Type: "string"}},
+		},
+	}
+}
+
+func (b *openApiBuilder) add(method, path string, op *OpenApiOperation) {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = make(OpenApiPathItem)
+		b.doc.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = op
+}
+
+// addLocalized documents an operation under its {lang} path and under the path without the
+// segment, where the language is negotiated from Accept-Language.
+func (b *openApiBuilder) addLocalized(method, path string, op *OpenApiOperation) {
+	localized := *op
+	localized.Parameters = append([]OpenApiParameter{langParam()}, op.Parameters...)
+	if _, ok := op.Responses["400"]; !ok {
+		localized.Responses = make(map[string]OpenApiResponse, len(op.Responses)+1)
+		for status, response := range op.Responses {
+			localized.Responses[status] = response
+		}
+		localized.Responses["400"] = b.errorResponse("Unsupported language.")
+	}
+	b.add(method, path, &localized)
+
+	negotiated := *op
+	negotiated.OperationId = op.OperationId + "-negotiated"
+	negotiated.Parameters = append([]OpenApiParameter{acceptLanguageParam()}, op.Parameters...)
+	b.add(method, strings.Replace(path, "/{lang}", "", 1), &negotiated)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringParam(name, in, description string, required bool) OpenApiParameter {
+	return OpenApiParameter{Name: name, In: in, Description: description, Required: required, Schema: &OpenApiSchema{Type: "string"}}
+}
+
+func langParam() OpenApiParameter {
+	return OpenApiParameter{
+		Name:        "lang",
+		In:          "path",
+		Description: "Language of the translated fields.",
+		Required:    true,
+		Schema:      &OpenApiSchema{Type: "string", Enum: Languages},
+	}
+}
+
+func acceptLanguageParam() OpenApiParameter {
+	return OpenApiParameter{
+		Name:        "Accept-Language",
+		In:          "header",
+		Description: fmt.Sprintf("Preferred languages, the best supported one is used. Defaults to %s.", DefaultLanguage),
+		Schema:      &OpenApiSchema{Type: "string"},
+	}
+}
+
+func pageParams() []OpenApiParameter {
+	return []OpenApiParameter{
+		{Name: "page[number]", In: "query", Description: "Page to return, starting at 1.", Schema: &OpenApiSchema{Type: "integer", Minimum: intPtr(1), Default: 1}},
+		{Name: "page[size]", In: "query", Description: "Items per page.", Schema: &OpenApiSchema{Type: "integer", Minimum: intPtr(1), Maximum: intPtr(MaxPageSize), Default: DefaultPageSize}},
+	}
+}
+
+func rangeParams() []OpenApiParameter {
+	return []OpenApiParameter{
+		{Name: "range[start_date]", In: "query", Description: "First day, yyyy-mm-dd. Defaults to today.", Schema: &OpenApiSchema{Type: "string", Format: "date"}},
+		{Name: "range[end_date]", In: "query", Description: "Last day (inclusive), yyyy-mm-dd. Defaults to the start date.", Schema: &OpenApiSchema{Type: "string", Format: "date"}},
+		{Name: "timezone", In: "query", Description: "Timezone used to compute today.", Schema: &OpenApiSchema{Type: "string", Default: "Europe/Paris"}},
+		stringParam("filter[bonus.type_name]", "query", "Only days with this bonus type id.", false),
+	}
+}
+
+func buildOpenApi() *OpenApiDocument {
+	b := &openApiBuilder{doc: &OpenApiDocument{
+		OpenApi: "3.0.3",
+		Info: OpenApiInfo{
+			Title:       "dodualm",
+			Description: DodualmLong,
+			Version:     DodudaVersion,
+		},
+		Servers: []OpenApiServer{{Url: fmt.Sprintf("%s://%s/dofus3/v1", ApiScheme, ApiHostName)}},
+		Paths:   make(map[string]OpenApiPathItem),
+		Components: OpenApiComponents{
+			Schemas: make(map[string]*OpenApiSchema),
+			SecuritySchemes: map[string]OpenApiSecurityScheme{
+				"updateToken": {Type: "http", Scheme: "bearer"},
+				"adminToken":  {Type: "http", Scheme: "bearer", Description: "A token of ADMIN_TOKENS, its name is recorded as author of the edits."},
+				"apiKey":      {Type: "apiKey", In: "header", Name: ApiKeyHeader, Description: "Optional, raises the rate limit above the per IP default."},
+			},
+		},
+	}}
+
+	badRequest := b.errorResponse("Invalid parameters.")
+	serverError := b.errorResponse("Server error.")
+	notModified := OpenApiResponse{Description: "The cached copy is still fresh."}
+
+	b.add(http.MethodGet, "/openapi.json", &OpenApiOperation{
+		OperationId: "get-openapi",
+		Summary:     "This OpenAPI document",
+		Tags:        []string{"Meta"},
+		Responses: map[string]OpenApiResponse{
+			"200": {Description: "OpenAPI 3 document.", Content: jsonContent(&OpenApiSchema{Type: "object"})},
+		},
+	})
+
+	b.add(http.MethodGet, "/docs", &OpenApiOperation{
+		OperationId: "get-docs",
+		Summary:     "Interactive documentation",
+		Tags:        []string{"Meta"},
+		Responses: map[string]OpenApiResponse{
+			"200": {Description: "HTML page rendering this document.", Content: map[string]OpenApiMediaType{"text/html": {Schema: &OpenApiSchema{Type: "string"}}}},
+		},
+	})
+
+	b.add(http.MethodGet, "/healthz", &OpenApiOperation{
+		OperationId: "get-health",
+		Summary:     "Liveness probe",
+		Tags:        []string{"Meta"},
+		Responses: map[string]OpenApiResponse{
+			"200": {Description: "The process is alive."},
+		},
+	})
+
+	b.add(http.MethodGet, "/readyz", &OpenApiOperation{
+		OperationId: "get-readiness",
+		Summary:     "Readiness probe",
+		Description: "Checks the database, its schema version, today's almanax and Meilisearch. An unavailable Meilisearch only degrades the status.",
+		Tags:        []string{"Meta"},
+		Responses: map[string]OpenApiResponse{
+			"200": b.jsonResponse("Ready, the status is ok or degraded.", ReadinessResponse{}),
+			"503": b.jsonResponse("A required check failed.", ReadinessResponse{}),
+		},
+	})
+
+	b.add(http.MethodGet, "/meta/almanax/info", &OpenApiOperation{
+		OperationId: "get-almanax-info",
+		Summary:     "Provenance of the served data",
+		Description: "The dofus3-main release, asset and dodualm version of the latest import.",
+		Tags:        []string{"Meta"},
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("Latest import.", jsonContent(b.schema(AlmanaxInfoResponse{}))),
+			"304": notModified,
+			"404": b.errorResponse("Nothing was imported yet."),
+			"500": serverError,
+		},
+	})
+
+	b.add(http.MethodGet, "/almanax/coverage", &OpenApiOperation{
+		OperationId: "get-almanax-coverage",
+		Summary:     "Dates covered by the local data",
+		Tags:        []string{"Meta"},
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("Coverage of the almanax data.", jsonContent(b.schema(AlmanaxCoverageResponse{}))),
+			"304": notModified,
+			"500": serverError,
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/meta/{lang}/almanax/bonuses", &OpenApiOperation{
+		OperationId: "get-almanax-bonuses",
+		Summary:     "List all bonus types",
+		Tags:        []string{"Meta"},
+		Parameters:  pageParams(),
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("Page of bonus types.", jsonContent(b.schema(ApiPage[AlmanaxBonusListing]{}))),
+			"304": notModified,
+			"400": badRequest,
+			"500": serverError,
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/meta/{lang}/almanax/bonuses/search", &OpenApiOperation{
+		OperationId: "search-almanax-bonuses",
+		Summary:     "Search bonus types by name",
+		Tags:        []string{"Meta"},
+		Parameters: []OpenApiParameter{
+			stringParam("query", "query", "Search term.", true),
+			{Name: "limit", In: "query", Description: "Maximum number of results.", Schema: &OpenApiSchema{Type: "integer", Minimum: intPtr(1), Maximum: intPtr(100), Default: 8}},
+		},
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("Matching bonus types. A Warning header marks stale results served while Meilisearch is unavailable.", jsonContent(b.schema([]AlmanaxBonusListing{}))),
+			"304": notModified,
+			"400": badRequest,
+			"404": b.errorResponse("Nothing found."),
+			"500": serverError,
+			"503": b.errorResponse("Meilisearch is unavailable and there is no stale result."),
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/{lang}/almanax", &OpenApiOperation{
+		OperationId: "get-almanax-range",
+		Summary:     "Almanax days in a date range",
+		Description: "Returns today in the requested timezone when no range is given.",
+		Tags:        []string{"Almanax"},
+		Parameters:  append(rangeParams(), pageParams()...),
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("Page of almanax days sorted by date.", jsonContent(b.schema(ApiPage[AlmanaxResponse]{}))),
+			"304": notModified,
+			"400": badRequest,
+			"500": serverError,
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/{lang}/almanax/{date}", &OpenApiOperation{
+		OperationId: "get-almanax-date",
+		Summary:     "A single almanax day",
+		Tags:        []string{"Almanax"},
+		Parameters: []OpenApiParameter{
+			{Name: "date", In: "path", Description: "Day in yyyy-mm-dd.", Required: true, Schema: &OpenApiSchema{Type: "string", Format: "date"}},
+		},
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("The almanax day.", jsonContent(b.schema(AlmanaxResponse{}))),
+			"304": notModified,
+			"400": badRequest,
+			"404": b.errorResponse("No data for this day."),
+			"500": serverError,
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/{lang}/almanax/ics", &OpenApiOperation{
+		OperationId: "get-almanax-ics",
+		Summary:     "iCalendar feed of the upcoming days",
+		Tags:        []string{"Feeds"},
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("iCalendar file.", map[string]OpenApiMediaType{"text/calendar": {Schema: &OpenApiSchema{Type: "string"}}}),
+			"304": notModified,
+			"500": serverError,
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/{lang}/almanax/rss", &OpenApiOperation{
+		OperationId: "get-almanax-rss",
+		Summary:     "RSS feed of the upcoming days",
+		Tags:        []string{"Feeds"},
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("RSS 2.0 feed.", map[string]OpenApiMediaType{"application/rss+xml": {Schema: &OpenApiSchema{Type: "string"}}}),
+			"304": notModified,
+			"500": serverError,
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/{lang}/almanax/search", &OpenApiOperation{
+		OperationId: "search-almanax",
+		Summary:     "Search days by tribute item, bonus type or description",
+		Description: "Results are sorted by date. A Warning header marks stale results served while Meilisearch is unavailable.",
+		Tags:        []string{"Almanax"},
+		Parameters: append([]OpenApiParameter{
+			stringParam("query", "query", "Search term.", true),
+			stringParam("filter[bonus.type_name]", "query", "Only days with this bonus type id.", false),
+			stringParam("filter[tribute.item.subtype]", "query", "Only days with this tribute item subtype.", false),
+			{Name: "filter[future]", In: "query", Description: "Only today and later days.", Schema: &OpenApiSchema{Type: "boolean", Default: false}},
+		}, pageParams()...),
+		Responses: map[string]OpenApiResponse{
+			"200": b.cachedResponse("Page of matching days with facet counts.", jsonContent(b.schema(AlmanaxSearchResponse{}))),
+			"304": notModified,
+			"400": badRequest,
+			"500": serverError,
+			"503": b.errorResponse("Meilisearch is unavailable and there is no stale result."),
+		},
+	})
+
+	b.addLocalized(http.MethodGet, "/{lang}/almanax/export", &OpenApiOperation{
+		OperationId: "export-almanax",
+		Summary:     "Stream a date range as csv or ndjson",
+		Tags:        []string{"Almanax"},
+		Parameters: append([]OpenApiParameter{
+			{Name: "format", In: "query", Description: "Export format.", Schema: &OpenApiSchema{Type: "string", Enum: ExportFormats, Default: "csv"}},
+		}, rangeParams()...),
+		Responses: map[string]OpenApiResponse{
+			"200": {Description: "One row per day.", Content: map[string]OpenApiMediaType{
+				"text/csv":             {Schema: &OpenApiSchema{Type: "string"}},
+				"application/x-ndjson": {Schema: b.schema(AlmanaxExportRow{})},
+			}},
+			"400": badRequest,
+		},
+	})
+
+	b.add(http.MethodPut, "/{lang}/almanax/{lang}", &OpenApiOperation{
+		OperationId: "update-almanax",
+		Summary:     "Import a dofus3-main release",
+		Description: "Webhook for new releases. The import runs in the background. Both path segments take a supported language.",
+		Tags:        []string{"Update"},
+		Parameters:  []OpenApiParameter{langParam()},
+		RequestBody: &OpenApiRequestBody{Content: jsonContent(b.schema(UpdateAlmanaxRequest{}))},
+		Security:    []map[string][]string{{"updateToken": {}}},
+		Responses: map[string]OpenApiResponse{
+			"202": {Description: "Import started."},
+			"400": badRequest,
+			"401": b.errorResponse("Missing or wrong update token."),
+		},
+	})
+
+	b.addAdmin(badRequest, serverError)
+
+	b.addRateLimits()
+
+	return b.doc
+}
+
+// addAdmin documents the /admin routes for manual corrections. Every edit records the admin as author and keeps the row
+// from being overwritten by imports.
+func (b *openApiBuilder) addAdmin(badRequest, serverError OpenApiResponse) {
+	const keptUpdate = "Replaces the fields and the given translations and restores a deleted row. The row is kept by later imports."
+	security := []map[string][]string{{"adminToken": {}}}
+	unauthorized := b.errorResponse("Missing or wrong admin token.")
+	notFound := b.errorResponse("No such row or it is deleted already.")
+	conflict := b.errorResponse("The row exists already or is still in use.")
+	dateParam := OpenApiParameter{Name: "date", In: "path", Description: "Day in yyyy-mm-dd.", Required: true, Schema: &OpenApiSchema{Type: "string", Format: "date"}}
+	idParam := OpenApiParameter{Name: "id", In: "path", Description: "Database id of the row.", Required: true, Schema: &OpenApiSchema{Type: "integer"}}
+
+	resources := []struct {
+		path, name, operation string
+		param                 OpenApiParameter
+		body                  any
+		update                string
+	}{
+		{"/admin/almanax", "an almanax day", "almanax", dateParam, AdminAlmanax{}, keptUpdate + " Days before today cannot be saved or deleted."},
+		{"/admin/bonus-types", "a bonus type", "bonus-type", idParam, AdminBonusType{}, keptUpdate},
+		{"/admin/bonuses", "a bonus", "bonus", idParam, AdminBonus{}, keptUpdate},
+		{"/admin/tributes", "a tribute", "tribute", idParam, AdminTribute{}, keptUpdate},
+		{"/admin/overrides", "an override", "override", idParam, AdminOverride{},
+			"Replaces the pinned bonus and tribute, the reason and the expiry and restores a deleted override. The release check starts over."},
+	}
+	for _, resource := range resources {
+		body := &OpenApiRequestBody{Content: jsonContent(b.schema(resource.body))}
+		item := resource.path + "/{" + resource.param.Name + "}"
+
+		b.add(http.MethodPost, resource.path, &OpenApiOperation{
+			OperationId: "admin-create-" + resource.operation,
+			Summary:     "Create " + resource.name,
+			Tags:        []string{"Admin"},
+			RequestBody: body,
+			Security:    security,
+			Responses: map[string]OpenApiResponse{
+				"201": b.jsonResponse("The created row.", resource.body),
+				"400": badRequest,
+				"401": unauthorized,
+				"409": conflict,
+				"500": serverError,
+			},
+		})
+
+		b.add(http.MethodPut, item, &OpenApiOperation{
+			OperationId: "admin-update-" + resource.operation,
+			Summary:     "Replace " + resource.name,
+			Description: resource.update,
+			Tags:        []string{"Admin"},
+			Parameters:  []OpenApiParameter{resource.param},
+			RequestBody: body,
+			Security:    security,
+			Responses: map[string]OpenApiResponse{
+				"200": b.jsonResponse("The updated row.", resource.body),
+				"400": badRequest,
+				"401": unauthorized,
+				"404": notFound,
+				"500": serverError,
+			},
+		})
+
+		b.add(http.MethodDelete, item, &OpenApiOperation{
+			OperationId: "admin-delete-" + resource.operation,
+			Summary:     "Soft-delete " + resource.name,
+			Tags:        []string{"Admin"},
+			Parameters:  []OpenApiParameter{resource.param},
+			Security:    security,
+			Responses: map[string]OpenApiResponse{
+				"204": {Description: "Deleted."},
+				"400": badRequest,
+				"401": unauthorized,
+				"404": notFound,
+				"409": conflict,
+				"500": serverError,
+			},
+		})
+	}
+
+	b.add(http.MethodGet, "/admin/overrides", &OpenApiOperation{
+		OperationId: "admin-list-overrides",
+		Summary:     "List overrides",
+		Description: "Overrides pin the bonus and/or the tribute of a day until they expire. Read endpoints serve them over the imported data " +
+			"and mark the day with an override object. Imports record whether their release agrees with the pinned values.",
+		Tags: []string{"Admin"},
+		Parameters: []OpenApiParameter{
+			{Name: "all", In: "query", Description: "Include expired and deleted overrides.", Schema: &OpenApiSchema{Type: "boolean"}},
+		},
+		Security: security,
+		Responses: map[string]OpenApiResponse{
+			"200": b.jsonResponse("Overrides sorted by date.", []AdminOverride{}),
+			"401": unauthorized,
+			"500": serverError,
+		},
+	})
+}
+
+// rateLimitExempt are the paths outside of the rateLimit middleware.
+var rateLimitExempt = []string{"/openapi.json", "/docs", "/healthz", "/readyz"}
+
+// addRateLimits documents the 429 answer and the optional API key on every rate limited operation.
+func (b *openApiBuilder) addRateLimits() {
+	rateLimited := OpenApiResponse{
+		Description: "Rate limit exceeded, retry after the Retry-After header.",
+		Content:     jsonContent(b.schema(ApiError{})),
+		Headers: map[string]OpenApiHeader{
+			"Retry-After":         {Description: "Seconds until the next request is allowed.", Schema: &OpenApiSchema{Type: "integer"}},
+			"RateLimit-Limit":     {Description: "Requests the client can send at once.", Schema: &OpenApiSchema{Type: "integer"}},
+			"RateLimit-Remaining": {Description: "Requests left right now.", Schema: &OpenApiSchema{Type: "integer"}},
+			"RateLimit-Reset":     {Description: "Seconds until the limit is fully available again.", Schema: &OpenApiSchema{Type: "integer"}},
+		},
+	}
+	for path, item := range b.doc.Paths {
+		if sliceContains(rateLimitExempt, path) {
+			continue
+		}
+		for _, op := range item {
+			op.Responses["429"] = rateLimited
+			if _, ok := op.Responses["401"]; !ok {
+				op.Responses["401"] = b.errorResponse("Unknown or revoked API key.")
+			}
+			if op.Security == nil {
+				op.Security = []map[string][]string{{}, {"apiKey": {}}}
+			}
+		}
+	}
+}
+
+func RetrieveOpenApi(w http.ResponseWriter, r *http.Request) {
+	SetJsonHeader(&w)
+	if err := writeJson(w, buildOpenApi()); err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
+}
+
+//go:embed docs.html
+var apiDocsHtml []byte
+
+func RetrieveApiDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(apiDocsHtml)
+}
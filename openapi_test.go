@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenApiCoversRoutes(t *testing.T) {
+	doc := buildOpenApi()
+
+	documented := make(map[string]bool)
+	err := chi.Walk(Router(), func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		path := strings.TrimPrefix(route, "/dofus3/v1")
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+		documented[strings.ToLower(method)+" "+path] = true
+
+		item, ok := doc.Paths[path]
+		if assert.True(t, ok, "route %s %s is not described in the OpenAPI document", method, route) {
+			assert.NotNil(t, item[strings.ToLower(method)], "route %s %s is not described in the OpenAPI document", method, route)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			assert.True(t, documented[method+" "+path], "OpenAPI document describes %s %s which is not routed", method, path)
+		}
+	}
+}
+
+func TestOpenApiSchemas(t *testing.T) {
+	doc := buildOpenApi()
+
+	page, ok := doc.Components.Schemas["AlmanaxResponsePage"]
+	if assert.True(t, ok) {
+		assert.Equal(t, "#/components/schemas/PaginationLinks", page.Properties["_links"].Ref)
+		assert.Equal(t, "#/components/schemas/AlmanaxResponse", page.Properties["items"].Items.Ref)
+	}
+
+	apiError := doc.Components.Schemas["ApiError"]
+	assert.ElementsMatch(t, []string{"status", "error", "code", "message"}, apiError.Required)
+
+	search := doc.Components.Schemas["AlmanaxSearchResponse"]
+	assert.Contains(t, search.Properties, "_links")
+	assert.Contains(t, search.Properties, "facets")
+
+	links := doc.Components.Schemas["PaginationLinks"]
+	assert.True(t, links.Properties["next"].Nullable)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// overrideTimeLayout is how expires_at is stored, so it compares with datetime('now') in the queries.
+const overrideTimeLayout = "2006-01-02 15:04:05"
+
+// AdminOverride pins the bonus and/or the tribute of a day until it expires. Read endpoints serve it instead of the
+// imported data and mark the day with the reason. Imports leave it alone and record if their release agrees.
+type AdminOverride struct {
+	Id            int64     `json:"id"`
+	Date          string    `json:"date"`
+	BonusId       *int64    `json:"bonus_id,omitempty"`
+	TributeId     *int64    `json:"tribute_id,omitempty"`
+	Reason        string    `json:"reason"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	ReleaseTag    *string   `json:"release_tag,omitempty"`    // latest import that had the date, set by imports
+	ReleaseAgrees *bool     `json:"release_agrees,omitempty"` // if that release had the pinned bonus and tribute
+	AdminEdit
+}
+
+func adminOverride(override *Override) AdminOverride {
+	return AdminOverride{
+		Id:            override.ID,
+		Date:          override.Date,
+		BonusId:       override.BonusID,
+		TributeId:     override.TributeID,
+		Reason:        override.Reason,
+		ExpiresAt:     override.ExpiresAt,
+		ReleaseTag:    override.ReleaseTag,
+		ReleaseAgrees: override.ReleaseAgrees,
+		AdminEdit: AdminEdit{
+			EditedBy:  override.EditedBy,
+			EditedAt:  override.EditedAt,
+			DeletedAt: override.DeletedAt,
+		},
+	}
+}
+
+const overrideColumns = `id, date, bonus_id, tribute_id, reason, expires_at, release_tag, release_agrees, edited_by, edited_at, created_at, updated_at, deleted_at`
+
+func scanOverride(row interface{ Scan(...any) error }) (*Override, error) {
+	var override Override
+	err := row.Scan(&override.ID, &override.Date, &override.BonusID, &override.TributeID, &override.Reason, &override.ExpiresAt,
+		&override.ReleaseTag, &override.ReleaseAgrees, &override.EditedBy, &override.EditedAt, &override.CreatedAt, &override.UpdatedAt,
+		&override.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func getOverride(tx *sql.Tx, id int64) (*Override, error) {
+	override, err := scanOverride(tx.QueryRow(`SELECT `+overrideColumns+` FROM overrides WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no override %d", errAdminNotFound, id)
+	}
+	return override, err
+}
+
+// ListOverrides returns the active overrides by date, with all also the expired and deleted ones.
+func (r *Repository) ListOverrides(ctx context.Context, all bool) ([]Override, error) {
+	defer observeQuery(ctx, "overrides")()
+
+	query := `SELECT ` + overrideColumns + ` FROM overrides`
+	if !all {
+		query += ` WHERE deleted_at IS NULL AND expires_at > datetime('now')`
+	}
+	query += ` ORDER BY date ASC, id ASC`
+
+	rows, err := r.Db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []Override
+	for rows.Next() {
+		override, err := scanOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, *override)
+	}
+	return overrides, rows.Err()
+}
+
+// CountActiveOverrides returns the number of active overrides on the days from..to.
+func (r *Repository) CountActiveOverrides(ctx context.Context, from, to string) (int, error) {
+	defer observeQuery(ctx, "count_active_overrides")()
+
+	var count int
+	err := r.Db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM overrides WHERE deleted_at IS NULL AND expires_at > datetime('now') AND date >= ? AND date <= ?`,
+		from, to).Scan(&count)
+	return count, err
+}
+
+// overridesUsing returns the number of active overrides whose column is id.
+func overridesUsing(tx *sql.Tx, column string, id int64) (int, error) {
+	var count int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM overrides WHERE deleted_at IS NULL AND expires_at > datetime('now') AND `+column+` = ?`, id).
+		Scan(&count)
+	return count, err
+}
+
+// SaveOverride creates the override when override.ID is 0, otherwise it replaces it. The date must have an almanax
+// from today on and a replaced override can not be moved away from a past date, an expired override on the same
+// date is deleted to make room. It returns the days to refresh,
+// which include the old date of a moved override.
+func (r *Repository) SaveOverride(ctx context.Context, override *Override, today string) (*Override, []string, error) {
+	if _, err := time.Parse(DateLayout, override.Date); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid date, expected yyyy-mm-dd", errAdminInvalidData)
+	}
+	if override.Date < today {
+		return nil, nil, fmt.Errorf("%w: %s is in the past, past days are served as immutable", errAdminInvalidData, override.Date)
+	}
+	if override.BonusID == nil && override.TributeID == nil {
+		return nil, nil, fmt.Errorf("%w: an override needs a bonus_id, a tribute_id or both", errAdminInvalidData)
+	}
+	if override.Reason == "" {
+		return nil, nil, fmt.Errorf("%w: an override needs a reason", errAdminInvalidData)
+	}
+	if !override.ExpiresAt.After(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: expires_at has to be in the future", errAdminInvalidData)
+	}
+
+	var saved *Override
+	dates := []string{override.Date}
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		if override.BonusID != nil {
+			if err := requireActive(tx, "bonus", *override.BonusID, "bonus_id"); err != nil {
+				return err
+			}
+		}
+		if override.TributeID != nil {
+			if err := requireActive(tx, "tribute", *override.TributeID, "tribute_id"); err != nil {
+				return err
+			}
+		}
+		almanax, _, err := getAdminAlmanax(tx, override.Date)
+		if err != nil && !errors.Is(err, errAdminNotFound) {
+			return err
+		}
+		if almanax == nil || almanax.DeletedAt != nil {
+			return fmt.Errorf("%w: there is no almanax on %s to override", errAdminInvalidData, override.Date)
+		}
+
+		var otherId int64
+		var expired bool
+		err = tx.QueryRow(`SELECT id, expires_at <= datetime('now') FROM overrides WHERE date = ? AND deleted_at IS NULL AND id != ?`,
+			override.Date, override.ID).Scan(&otherId, &expired)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return err
+		case !expired:
+			return fmt.Errorf("%w: override %d already pins %s, replace it with PUT", errAdminConflict, otherId, override.Date)
+		default:
+			if err = softDelete(tx, "overrides", otherId, override.EditedBy); err != nil {
+				return err
+			}
+		}
+
+		expiresAt := override.ExpiresAt.UTC().Format(overrideTimeLayout)
+		if override.ID == 0 {
+			result, err := tx.Exec(`
+				INSERT INTO overrides (date, bonus_id, tribute_id, reason, expires_at, edited_by, edited_at, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'), datetime('now'))`,
+				override.Date, override.BonusID, override.TributeID, override.Reason, expiresAt, override.EditedBy)
+			if err != nil {
+				return err
+			}
+			if override.ID, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		} else {
+			previous, err := getOverride(tx, override.ID)
+			if err != nil {
+				return err
+			}
+			if previous.Date < today {
+				return fmt.Errorf("%w: override %d is on %s in the past, past days are served as immutable", errAdminInvalidData,
+					override.ID, previous.Date)
+			}
+			if previous.Date != override.Date {
+				dates = append(dates, previous.Date)
+			}
+			// the release check was about the old values
+			if _, err = tx.Exec(`
+				UPDATE overrides SET date = ?, bonus_id = ?, tribute_id = ?, reason = ?, expires_at = ?, release_tag = NULL, release_agrees = NULL,
+					deleted_at = NULL, edited_by = ?, edited_at = datetime('now'), updated_at = datetime('now')
+				WHERE id = ?`,
+				override.Date, override.BonusID, override.TributeID, override.Reason, expiresAt, override.EditedBy, override.ID); err != nil {
+				return err
+			}
+		}
+
+		saved, err = getOverride(tx, override.ID)
+		return err
+	})
+	return saved, dates, err
+}
+
+// DeleteOverride soft-deletes the override and returns its date, which is served from the imported data again.
+// Overrides on past days stay, past days are served as immutable.
+func (r *Repository) DeleteOverride(ctx context.Context, id int64, author, today string) (string, error) {
+	var date string
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		if err := requireDeletable(tx, "overrides", id); err != nil {
+			return err
+		}
+		override, err := getOverride(tx, id)
+		if err != nil {
+			return err
+		}
+		if override.Date < today {
+			return fmt.Errorf("%w: override %d is on %s in the past, past days are served as immutable", errAdminInvalidData, id, override.Date)
+		}
+		date = override.Date
+		return softDelete(tx, "overrides", id, author)
+	})
+	return date, err
+}
+
+func ListAdminOverrides(w http.ResponseWriter, r *http.Request) {
+	all := r.URL.Query().Get("all") == "true"
+	overrides, err := Database.ListOverrides(r.Context(), all)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query overrides: "+err.Error())
+		return
+	}
+
+	res := make([]AdminOverride, 0, len(overrides))
+	for i := range overrides {
+		res = append(res, adminOverride(&overrides[i]))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = writeJson(w, res); err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+	}
+}
+
+func saveAdminOverride(w http.ResponseWriter, r *http.Request, id int64) {
+	var request AdminOverride
+	if !decodeAdminRequest(w, r, &request) {
+		return
+	}
+
+	today, err := currentDate("")
+	if err != nil {
+		writeServerErrorResponse(w, "Could not determine today: "+err.Error())
+		return
+	}
+
+	override := Override{
+		ID:        id,
+		Date:      request.Date,
+		BonusID:   request.BonusId,
+		TributeID: request.TributeId,
+		Reason:    request.Reason,
+		ExpiresAt: request.ExpiresAt,
+		EditedBy:  r.Context().Value("author").(string),
+	}
+	saved, dates, err := Database.SaveOverride(r.Context(), &override, today.Format(DateLayout))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if id == 0 {
+		status = http.StatusCreated
+	}
+	writeAdminResponse(w, r, status, dates, adminOverride(saved))
+}
+
+func CreateAdminOverride(w http.ResponseWriter, r *http.Request) {
+	saveAdminOverride(w, r, 0)
+}
+
+func UpdateAdminOverride(w http.ResponseWriter, r *http.Request) {
+	if id, ok := adminIdParam(w, r); ok {
+		saveAdminOverride(w, r, id)
+	}
+}
+
+func DeleteAdminOverride(w http.ResponseWriter, r *http.Request) {
+	id, ok := adminIdParam(w, r)
+	if !ok {
+		return
+	}
+	today, err := currentDate("")
+	if err != nil {
+		writeServerErrorResponse(w, "Could not determine today: "+err.Error())
+		return
+	}
+	date, err := Database.DeleteOverride(r.Context(), id, r.Context().Value("author").(string), today.Format(DateLayout))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminResponse(w, r, http.StatusNoContent, []string{date}, nil)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverrideFields(t *testing.T) {
+	id := int64(3)
+	assert.Equal(t, []string{"bonus"}, (&Override{BonusID: &id}).Fields())
+	assert.Equal(t, []string{"tribute"}, (&Override{TributeID: &id}).Fields())
+	assert.Equal(t, []string{"bonus", "tribute"}, (&Override{BonusID: &id, TributeID: &id}).Fields())
+}
+
+func TestRenderAlmanaxOverride(t *testing.T) {
+	var mapped MappedAlmanax
+	mapped.Almanax.Date = "2024-06-15"
+	assert.Nil(t, renderAlmanax(&mapped, "en").Override)
+
+	id := int64(3)
+	expiresAt := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	mapped.Override = &Override{BonusID: &id, Reason: "confirmed in game", ExpiresAt: expiresAt}
+	res := renderAlmanax(&mapped, "en")
+	assert.Equal(t, &AlmanaxResponseOverride{Fields: []string{"bonus"}, Reason: "confirmed in game", ExpiresAt: expiresAt}, res.Override)
+}
+
+func TestCacheExpiredOverride(t *testing.T) {
+	loads := 0
+	c := newTestCache(&loads)
+
+	_, err := c.GetAlmanaxByDateRange(context.Background(), "2024-06-15", "2024-06-15")
+	assert.NoError(t, err)
+	c.days["2024-06-15"].Override = &Override{ID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	c.GetAlmanaxByDateRange(context.Background(), "2024-06-15", "2024-06-15")
+	assert.Equal(t, 1, loads)
+
+	c.days["2024-06-15"].Override.ExpiresAt = time.Now().Add(-time.Second)
+	c.GetAlmanaxByDateRange(context.Background(), "2024-06-15", "2024-06-15")
+	assert.Equal(t, 2, loads, "a day with an expired override is loaded again")
+}
+
+func TestAlmanaxCacheInfoOverride(t *testing.T) {
+	updatedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	almanax := make([]MappedAlmanax, 1)
+	almanax[0].Almanax.Date = "2024-06-15"
+	almanax[0].Almanax.UpdatedAt = updatedAt
+	plain := almanaxCacheInfo(almanax, "en", false, 1)
+
+	almanax[0].Override = &Override{ID: 1, UpdatedAt: updatedAt.Add(time.Hour)}
+	overridden := almanaxCacheInfo(almanax, "en", false, 1)
+	assert.NotEqual(t, plain.ETag, overridden.ETag)
+	assert.Equal(t, updatedAt.Add(time.Hour), overridden.LastModified)
+
+	almanax[0].Override.UpdatedAt = updatedAt.Add(2 * time.Hour)
+	assert.NotEqual(t, overridden.ETag, almanaxCacheInfo(almanax, "en", false, 1).ETag)
+}
+
+func TestPastOverride(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	ctx := context.Background()
+	now, err := currentDate("")
+	assert.NoError(t, err)
+	today, _ := time.Parse(DateLayout, now.Format(DateLayout))
+	day := func(offset int) string {
+		return today.AddDate(0, 0, offset).Format(DateLayout)
+	}
+
+	_, err = repo.ImportAlmanax(ctx, []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, day(-3), day(-2), day(-1), day(0), day(1)),
+	}, ImportSource{ReleaseTag: "1.0.0"}, day(-10))
+	assert.NoError(t, err)
+	var bonusId int64
+	assert.NoError(t, repo.Db.QueryRow(`SELECT bonus_id FROM almanax WHERE date = ?`, day(-2)).Scan(&bonusId))
+	override := func(id int64, date string) *Override {
+		return &Override{ID: id, Date: date, BonusID: &bonusId, Reason: "event", ExpiresAt: time.Now().Add(time.Hour), EditedBy: "alice"}
+	}
+
+	// saved while the day was still ahead
+	past, _, err := repo.SaveOverride(ctx, override(0, day(-2)), day(-5))
+	assert.NoError(t, err)
+
+	_, err = repo.DeleteOverride(ctx, past.ID, "alice", day(0))
+	assert.ErrorIs(t, err, errAdminInvalidData)
+	_, _, err = repo.SaveOverride(ctx, override(past.ID, day(1)), day(0))
+	assert.ErrorIs(t, err, errAdminInvalidData, "moving the override would change the past day")
+
+	overrides, err := repo.ListOverrides(ctx, false)
+	assert.NoError(t, err)
+	if assert.Len(t, overrides, 1) {
+		assert.Equal(t, day(-2), overrides[0].Date)
+		assert.Nil(t, overrides[0].DeletedAt)
+	}
+
+	// the day changes back to the imported data when the override expires
+	assert.False(t, isImmutableRange(ctx, repo, day(-3), day(-1)))
+	_, err = repo.Writer.Exec(`UPDATE overrides SET expires_at = datetime('now', '-1 second') WHERE id = ?`, past.ID)
+	assert.NoError(t, err)
+	assert.True(t, isImmutableRange(ctx, repo, day(-3), day(-1)))
+
+	// overrides from today on can still be moved and deleted
+	current, _, err := repo.SaveOverride(ctx, override(0, day(0)), day(0))
+	assert.NoError(t, err)
+	_, dates, err := repo.SaveOverride(ctx, override(current.ID, day(1)), day(0))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{day(1), day(0)}, dates)
+	date, err := repo.DeleteOverride(ctx, current.ID, "alice", day(0))
+	assert.NoError(t, err)
+	assert.Equal(t, day(1), date)
+}
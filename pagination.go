@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+var (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+type Page struct {
+	Number int // starts at 1
+	Size   int
+}
+
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.Size
+}
+
+type PaginationLinks struct {
+	Self  string  `json:"self,omitempty"`
+	First string  `json:"first,omitempty"`
+	Prev  *string `json:"prev"`
+	Next  *string `json:"next"`
+	Last  string  `json:"last,omitempty"`
+}
+
+type ApiPage[T any] struct {
+	Links PaginationLinks `json:"_links"`
+	Total int             `json:"total"`
+	Items []T             `json:"items"`
+}
+
+func getPageInBoundary(numberStr, sizeStr string) (Page, error) {
+	page := Page{Number: 1, Size: DefaultPageSize}
+
+	var err error
+	if numberStr != "" {
+		if page.Number, err = strconv.Atoi(numberStr); err != nil || page.Number < 1 {
+			return Page{}, fmt.Errorf("invalid page number")
+		}
+	}
+
+	if sizeStr != "" {
+		if page.Size, err = strconv.Atoi(sizeStr); err != nil || page.Size < 1 {
+			return Page{}, fmt.Errorf("invalid page size")
+		}
+		if page.Size > MaxPageSize {
+			return Page{}, fmt.Errorf("page size is too high, maximum is %d", MaxPageSize)
+		}
+	}
+
+	return page, nil
+}
+
+func parsePage(r *http.Request) (Page, error) {
+	return getPageInBoundary(r.URL.Query().Get("page[number]"), r.URL.Query().Get("page[size]"))
+}
+
+func pageUrl(path string, query url.Values, page Page, number int) string {
+	q := url.Values{}
+	for key, values := range query {
+		q[key] = values
+	}
+	q.Set("page[number]", strconv.Itoa(number))
+	q.Set("page[size]", strconv.Itoa(page.Size))
+	return fmt.Sprintf("%s://%s%s?%s", ApiScheme, ApiHostName, path, q.Encode())
+}
+
+func createPaginationLinks(path string, query url.Values, page Page, total int) PaginationLinks {
+	lastNumber := (total + page.Size - 1) / page.Size
+	if lastNumber < 1 {
+		lastNumber = 1
+	}
+
+	links := PaginationLinks{
+		Self:  pageUrl(path, query, page, page.Number),
+		First: pageUrl(path, query, page, 1),
+		Last:  pageUrl(path, query, page, lastNumber),
+	}
+
+	if page.Number > 1 {
+		prev := pageUrl(path, query, page, min(page.Number-1, lastNumber))
+		links.Prev = &prev
+	}
+	if page.Number < lastNumber {
+		next := pageUrl(path, query, page, page.Number+1)
+		links.Next = &next
+	}
+
+	return links
+}
+
+// paginate cuts the page out of an already loaded list.
+func paginate[T any](items []T, page Page) []T {
+	start := page.Offset()
+	if start >= len(items) {
+		return []T{}
+	}
+	end := min(start+page.Size, len(items))
+	return items[start:end]
+}
+
+func newApiPage[T any](r *http.Request, items []T, page Page, total int) ApiPage[T] {
+	return newApiPageAt(r.URL.Path, r.URL.Query(), items, page, total)
+}
+
+// newApiPageAt builds the page served for path and query. The static files use it to match the API.
+func newApiPageAt[T any](path string, query url.Values, items []T, page Page, total int) ApiPage[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return ApiPage[T]{
+		Links: createPaginationLinks(path, query, page, total),
+		Total: total,
+		Items: items,
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPageInBoundary(t *testing.T) {
+	page, err := getPageInBoundary("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, Page{Number: 1, Size: DefaultPageSize}, page)
+
+	page, err = getPageInBoundary("3", "10")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, page.Offset())
+
+	_, err = getPageInBoundary("0", "10")
+	assert.Error(t, err)
+
+	_, err = getPageInBoundary("1", "abc")
+	assert.Error(t, err)
+
+	_, err = getPageInBoundary("1", "101")
+	assert.Error(t, err)
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	assert.Equal(t, []int{1, 2}, paginate(items, Page{Number: 1, Size: 2}))
+	assert.Equal(t, []int{5}, paginate(items, Page{Number: 3, Size: 2}))
+	assert.Equal(t, []int{}, paginate(items, Page{Number: 4, Size: 2}))
+}
+
+func TestCreatePaginationLinks(t *testing.T) {
+	ApiScheme = "https"
+	ApiHostName = "api.dofusdu.de"
+	query := url.Values{"range[start_date]": {"2024-01-01"}}
+
+	links := createPaginationLinks("/dofus3/v1/en/almanax", query, Page{Number: 2, Size: 10}, 25)
+	assert.Equal(t, "https://api.dofusdu.de/dofus3/v1/en/almanax?page%5Bnumber%5D=2&page%5Bsize%5D=10&range%5Bstart_date%5D=2024-01-01", links.Self)
+	assert.Contains(t, links.First, "page%5Bnumber%5D=1&")
+	assert.Contains(t, links.Last, "page%5Bnumber%5D=3&")
+	assert.Contains(t, *links.Prev, "page%5Bnumber%5D=1&")
+	assert.Contains(t, *links.Next, "page%5Bnumber%5D=3&")
+
+	links = createPaginationLinks("/dofus3/v1/en/almanax", query, Page{Number: 1, Size: 10}, 0)
+	assert.Nil(t, links.Prev)
+	assert.Nil(t, links.Next)
+	assert.Equal(t, links.First, links.Last)
+}
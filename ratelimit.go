@@ -0,0 +1,194 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const ApiKeyHeader = "X-Api-Key"
+
+// RateQuota is the refill rate and bucket size of a client. A zero PerMinute disables the limit.
+type RateQuota struct {
+	PerMinute int
+	Burst     int
+}
+
+func (q RateQuota) unlimited() bool {
+	return q.PerMinute <= 0
+}
+
+func (q RateQuota) burst() int {
+	if q.Burst <= 0 {
+		return q.PerMinute
+	}
+	return q.Burst
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // when the bucket refilled completely if nobody takes a token
+}
+
+// RateDecision is the outcome of taking a token and the values for the RateLimit-* headers.
+type RateDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, only set when not allowed
+}
+
+// RateLimiter keeps a token bucket per client. Buckets refill continuously with the rate of the quota
+// the client presents and hold at most its burst. Full buckets are dropped, they behave like new ones.
+type RateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+
+	SweepInterval time.Duration
+
+	now func() time.Time
+}
+
+var Limiter *RateLimiter
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets:       make(map[string]*tokenBucket),
+		SweepInterval: time.Minute,
+		now:           time.Now,
+	}
+}
+
+// Take removes a token from the bucket of client if there is one.
+func (l *RateLimiter) Take(client string, quota RateQuota) RateDecision {
+	burst := quota.burst()
+	rate := float64(quota.PerMinute) / 60 // tokens per second
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(burst), updated: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(float64(burst), bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+	bucket.updated = now
+
+	decision := RateDecision{Limit: burst}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsDuration((1 - bucket.tokens) / rate)
+	}
+	decision.Remaining = int(bucket.tokens)
+	decision.Reset = secondsDuration((float64(burst) - bucket.tokens) / rate)
+	bucket.full = now.Add(decision.Reset)
+	return decision
+}
+
+// sweep drops the buckets that refilled completely, so clients that went away do not pile up.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.SweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for client, bucket := range l.buckets {
+		if !bucket.full.After(now) {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ceilSeconds rounds up, a client waiting for the header value must not come back too early.
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// clientIP is the remote address without port. Behind a proxy, TRUST_PROXY_HEADERS lets
+// middleware.RealIP replace it with the forwarded address first.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimit takes a token per request. Requests with a valid X-Api-Key header draw from the bucket of the key
+// with its quota and count towards its usage when served, all others from the bucket of their IP with the default quota.
+// It answers the RateLimit-* headers on every response and 429 with Retry-After when the bucket is empty.
+func rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client := "ip:" + clientIP(r)
+		quota := DefaultRateQuota
+		kind := "ip"
+		var apiKeyId int64
+		if key := r.Header.Get(ApiKeyHeader); key != "" && ApiKeys != nil {
+			apiKey, err := ApiKeys.Lookup(r.Context(), key)
+			if err != nil {
+				log.FromContext(r.Context()).Error("could not look up api key", "err", err)
+				writeServerErrorResponse(w, "Could not check the API key.")
+				return
+			}
+			if apiKey == nil {
+				writeUnauthorizedResponse(w, "Unknown or revoked API key.")
+				return
+			}
+			client = "key:" + strconv.FormatInt(apiKey.ID, 10)
+			quota = apiKey.Quota()
+			kind = "key"
+			apiKeyId = apiKey.ID
+		}
+
+		serve := func() {
+			if apiKeyId != 0 {
+				ApiKeys.Record(apiKeyId)
+			}
+			next.ServeHTTP(w, r)
+		}
+
+		if quota.unlimited() {
+			serve()
+			return
+		}
+
+		decision := Limiter.Take(client, quota)
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(decision.Reset))
+		header.Set("RateLimit-Policy", strconv.Itoa(decision.Limit)+";w="+strconv.Itoa(max(1, 60*decision.Limit/quota.PerMinute)))
+		header.Add("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
+
+		if !decision.Allowed {
+			rateLimitedTotal.WithLabelValues(kind).Inc()
+			header.Set("Retry-After", ceilSeconds(decision.RetryAfter))
+			writeTooManyRequestsResponse(w, "Retry in "+ceilSeconds(decision.RetryAfter)+" seconds or use an API key with a higher quota.")
+			return
+		}
+		serve()
+	})
+}
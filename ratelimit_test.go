@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterTake(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter()
+	limiter.now = func() time.Time { return now }
+	quota := RateQuota{PerMinute: 60, Burst: 2}
+
+	first := limiter.Take("a", quota)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 2, first.Limit)
+	assert.Equal(t, 1, first.Remaining)
+	assert.Equal(t, time.Second, first.Reset)
+
+	assert.True(t, limiter.Take("a", quota).Allowed)
+	denied := limiter.Take("a", quota)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+	assert.Equal(t, 2*time.Second, denied.Reset)
+
+	assert.True(t, limiter.Take("b", quota).Allowed, "buckets are per client")
+
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Take("a", quota).Allowed)
+	assert.False(t, limiter.Take("a", quota).Allowed)
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter()
+	limiter.now = func() time.Time { return now }
+	slow := RateQuota{PerMinute: 1, Burst: 5}
+
+	limiter.Take("idle", RateQuota{PerMinute: 60, Burst: 1})
+	for i := 0; i < 5; i++ {
+		limiter.Take("slow", slow)
+	}
+
+	now = now.Add(2 * time.Minute)
+	limiter.Take("other", slow)
+	assert.NotContains(t, limiter.buckets, "idle")
+	assert.Contains(t, limiter.buckets, "slow", "a bucket that did not refill must not be reset")
+	assert.Equal(t, 1, limiter.Take("slow", slow).Remaining)
+}
+
+func withRateLimits(t *testing.T, quota RateQuota, keys map[string]*ApiKey) {
+	limiter, store := Limiter, ApiKeys
+	defaultQuota := DefaultRateQuota
+	t.Cleanup(func() { Limiter, ApiKeys, DefaultRateQuota = limiter, store, defaultQuota })
+
+	Limiter = NewRateLimiter()
+	DefaultRateQuota = quota
+	ApiKeys = &ApiKeyStore{
+		keys:  make(map[string]apiKeyEntry),
+		usage: make(map[int64]int64),
+		TTL:   time.Minute,
+		load: func(ctx context.Context, keyHash string) (*ApiKey, error) {
+			for key, apiKey := range keys {
+				if hashApiKey(key) == keyHash {
+					return apiKey, nil
+				}
+			}
+			return nil, nil
+		},
+		now: time.Now,
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	withRateLimits(t, RateQuota{PerMinute: 60, Burst: 1}, map[string]*ApiKey{
+		"dodualm_good": {ID: 7, RatePerMinute: 600, Burst: 3},
+	})
+	handler := rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "10.0.0.1:4242"
+		if key != "" {
+			req.Header.Set(ApiKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	ok := request("")
+	assert.Equal(t, http.StatusOK, ok.Code)
+	assert.Equal(t, "1", ok.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", ok.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", ok.Header().Get("RateLimit-Reset"))
+
+	limited := request("")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "1", limited.Header().Get("Retry-After"))
+	var apiErr ApiError
+	assert.NoError(t, json.NewDecoder(limited.Body).Decode(&apiErr))
+	assert.Equal(t, ERR_TOO_MANY_REQUESTS, apiErr.Code)
+
+	keyed := request("dodualm_good")
+	assert.Equal(t, http.StatusOK, keyed.Code, "keys do not share the bucket of the IP")
+	assert.Equal(t, "3", keyed.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, int64(1), ApiKeys.usage[7])
+
+	assert.Equal(t, http.StatusUnauthorized, request("dodualm_bad").Code)
+}
+
+func TestApiKeyStoreFlush(t *testing.T) {
+	var written map[int64]int64
+	fail := true
+	store := &ApiKeyStore{
+		usage: make(map[int64]int64),
+		flush: func(ctx context.Context, usage map[int64]int64, at time.Time) error {
+			if fail {
+				return errors.New("database is locked")
+			}
+			written = usage
+			return nil
+		},
+		now: time.Now,
+	}
+
+	store.Record(1)
+	store.Record(1)
+	assert.Error(t, store.Flush(context.Background()))
+	store.Record(2)
+
+	fail = false
+	assert.NoError(t, store.Flush(context.Background()))
+	assert.Equal(t, map[int64]int64{1: 2, 2: 1}, written)
+	assert.Empty(t, store.usage)
+}
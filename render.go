@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// The render functions are shared by the HTTP handlers and the static site generator,
+// so both always produce the same output for the same data.
+
+const DateLayout = "2006-01-02"
+
+// fallbackRecorder resolves translations and remembers every field that was not served in the requested language.
+type fallbackRecorder struct {
+	lang      string
+	fallbacks map[string]string
+}
+
+func (f *fallbackRecorder) resolve(field string, t Translations) string {
+	value, found := t.Resolve(f.lang)
+	if found != f.lang {
+		if f.fallbacks == nil {
+			f.fallbacks = make(map[string]string)
+		}
+		f.fallbacks[field] = found
+	}
+	return value
+}
+
+func renderAlmanax(mapped *MappedAlmanax, lang string) AlmanaxResponse {
+	tr := fallbackRecorder{lang: lang}
+	res := AlmanaxResponse{
+		Date: mapped.Almanax.Date,
+		Bonus: AlmanaxResponseBonus{
+			Description: tr.resolve("bonus.description", mapped.Bonus.Descriptions),
+			Type: AlmanaxResponseBonusType{
+				Id:   mapped.BonusType.NameID,
+				Name: tr.resolve("bonus.type.name", mapped.BonusType.Names),
+			},
+		},
+		Tribute: AlmanaxResponseTribute{
+			Item: AlmanaxResponseTributeItem{
+				AnkamaId:   mapped.Tribute.ItemAnkamaID,
+				Name:       tr.resolve("tribute.item.name", mapped.Tribute.ItemNames),
+				Subtype:    mapped.Tribute.ItemSubtype,
+				DoduapiUri: mapped.Tribute.ItemDoduapiUri,
+				ImageUrls: ApiImageUrls{
+					Icon: mapped.Tribute.ItemIcon,
+					Sd:   mapped.Tribute.ItemSd,
+					Hq:   mapped.Tribute.ItemHq,
+					Hd:   mapped.Tribute.ItemHd,
+				},
+			},
+			Quantity: mapped.Tribute.Quantity,
+		},
+		RewardKamas: mapped.Almanax.RewardKamas,
+	}
+	if mapped.Override != nil {
+		res.Override = &AlmanaxResponseOverride{
+			Fields:    mapped.Override.Fields(),
+			Reason:    mapped.Override.Reason,
+			ExpiresAt: mapped.Override.ExpiresAt,
+		}
+	}
+	res.Fallbacks = tr.fallbacks
+	return res
+}
+
+func renderAlmanaxList(mapped []MappedAlmanax, lang string) []AlmanaxResponse {
+	res := make([]AlmanaxResponse, 0, len(mapped))
+	for i := range mapped {
+		res = append(res, renderAlmanax(&mapped[i], lang))
+	}
+	return res
+}
+
+func renderBonusListing(bonusTypes []BonusType, lang string) []AlmanaxBonusListing {
+	res := make([]AlmanaxBonusListing, 0, len(bonusTypes))
+	for i := range bonusTypes {
+		tr := fallbackRecorder{lang: lang}
+		name := tr.resolve("name", bonusTypes[i].Names)
+		res = append(res, AlmanaxBonusListing{
+			Id:        bonusTypes[i].NameID,
+			Name:      name,
+			Fallbacks: tr.fallbacks,
+		})
+	}
+	return res
+}
+
+func writeJson(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false) // keeps the & in pagination links readable
+	return enc.Encode(v)
+}
+
+func icsEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
+
+// icsLine folds content lines longer than 75 octets as required by RFC 5545.
+func icsLine(sb *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && (line[cut]&0xC0) == 0x80 { // do not split utf8 sequences
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	sb.WriteString(line)
+	sb.WriteString("\r\n")
+}
+
+func writeAlmanaxIcs(w io.Writer, mapped []MappedAlmanax, lang string) error {
+	var sb strings.Builder
+	icsLine(&sb, "BEGIN:VCALENDAR")
+	icsLine(&sb, "VERSION:2.0")
+	icsLine(&sb, "PRODID:-//dofusdude//dodualm "+DodudaVersion+"//"+strings.ToUpper(lang))
+	icsLine(&sb, "CALSCALE:GREGORIAN")
+	icsLine(&sb, "X-WR-CALNAME:Dofus Almanax")
+
+	for i := range mapped {
+		alm := renderAlmanax(&mapped[i], lang)
+		date, err := time.Parse(DateLayout, alm.Date)
+		if err != nil {
+			return err
+		}
+
+		icsLine(&sb, "BEGIN:VEVENT")
+		icsLine(&sb, fmt.Sprintf("UID:%s-%s@dodualm", alm.Date, lang))
+		icsLine(&sb, "DTSTAMP:"+mapped[i].Almanax.UpdatedAt.UTC().Format("20060102T150405Z"))
+		icsLine(&sb, "DTSTART;VALUE=DATE:"+date.Format("20060102"))
+		icsLine(&sb, "DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format("20060102"))
+		icsLine(&sb, "SUMMARY:"+icsEscape(alm.Bonus.Type.Name))
+		icsLine(&sb, "DESCRIPTION:"+icsEscape(fmt.Sprintf("%s\n%d x %s", alm.Bonus.Description, alm.Tribute.Quantity, alm.Tribute.Item.Name)))
+		icsLine(&sb, "END:VEVENT")
+	}
+
+	icsLine(&sb, "END:VCALENDAR")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Description string `xml:"description"`
+	Guid        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Language    string    `xml:"language"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+func writeAlmanaxRss(w io.Writer, mapped []MappedAlmanax, lang string) error {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       "Dofus Almanax",
+			Link:        fmt.Sprintf("%s://%s/dofus3/v1/%s/almanax", ApiScheme, ApiHostName, lang),
+			Description: DodualmLong,
+			Language:    lang,
+		},
+	}
+
+	for i := range mapped {
+		alm := renderAlmanax(&mapped[i], lang)
+		date, err := time.Parse(DateLayout, alm.Date)
+		if err != nil {
+			return err
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       fmt.Sprintf("%s: %s", alm.Date, alm.Bonus.Type.Name),
+			Description: fmt.Sprintf("%s\n%d x %s", alm.Bonus.Description, alm.Tribute.Quantity, alm.Tribute.Item.Name),
+			Guid:        fmt.Sprintf("dodualm-%s-%s", lang, alm.Date),
+			PubDate:     date.Format(time.RFC1123Z),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(feed)
+}
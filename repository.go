@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/charmbracelet/log"
@@ -15,13 +18,16 @@ var repositoryMutex = sync.Mutex{}
 var DatabaseName = "almanax.db"
 
 type Repository struct {
-	Db  *sql.DB
-	ctx context.Context
+	Db     *sql.DB // read-only pool, never blocked by an in-progress write thanks to WAL
+	Writer *sql.DB // single connection, all writes go through WithTx
+	ctx    context.Context
 }
 
 func NewDatabaseRepository(ctx context.Context, workdir string) *Repository {
 	repo := Repository{}
-	repo.Init(ctx, workdir)
+	if err := repo.Init(ctx, workdir); err != nil {
+		log.Fatal("could not open database", "err", err)
+	}
 	return &repo
 }
 
@@ -42,11 +48,27 @@ func (r *Repository) Init(ctx context.Context, workdir string) error {
 		}
 	}
 
-	sqliteDatabase, err := sql.Open("sqlite3", dbpath)
+	pragmas := "_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on"
+
+	// the writer has to be opened first so the journal mode is switched before readers connect
+	writer, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?%s&_txlock=immediate", dbpath, pragmas))
+	if err != nil {
+		return err
+	}
+	writer.SetMaxOpenConns(1)
+	if err = writer.PingContext(ctx); err != nil {
+		writer.Close()
+		return err
+	}
+
+	reader, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?%s&_query_only=true", dbpath, pragmas))
 	if err != nil {
+		writer.Close()
 		return err
 	}
-	r.Db = sqliteDatabase
+
+	r.Db = reader
+	r.Writer = writer
 	r.ctx = ctx
 
 	return nil
@@ -54,148 +76,425 @@ func (r *Repository) Init(ctx context.Context, workdir string) error {
 
 func (r *Repository) Deinit() {
 	r.Db.Close()
+	r.Writer.Close()
 	r.Db = nil
+	r.Writer = nil
 }
 
-func (r *Repository) GetAlmanaxByDateRangeAndNameID(from, to, nameID string) ([]MappedAlmanax, error) {
-	query := `
-		SELECT
-			a.id, a.bonus_id, a.tribute_id, a.date, a.reward_kamas, a.created_at, a.updated_at, a.deleted_at,
-			b.id, b.bonus_type_id, b.description_en, b.description_fr, b.description_es, b.description_de, b.description_it, b.description_pt,
-			bt.id, bt.name_id, bt.name_en, bt.name_fr, bt.name_es, bt.name_de, bt.name_it, bt.name_pt,
-			t.id, t.item_name_en, t.item_name_fr, t.item_name_es, t.item_name_de, t.item_name_it, t.item_name_pt,
-			t.item_icon, t.item_sd, t.item_hq, t.item_hd, t.item_ankama_id, t.item_subtype, t.item_doduapi_uri, t.quantity
+// WithTx runs fn inside a write transaction. Writers are serialized, readers keep seeing the
+// last committed state until the transaction commits.
+func (r *Repository) WithTx(fn func(tx *sql.Tx) error) error {
+	return r.WithTxContext(r.ctx, fn)
+}
+
+// WithTxContext is WithTx bound to ctx, the transaction is rolled back when ctx is cancelled before it commits.
+func (r *Repository) WithTxContext(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	repositoryMutex.Lock()
+	defer repositoryMutex.Unlock()
+
+	tx, err := r.Writer.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Error("rollback failed", "err", rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// almanaxJoins merges an active override over the bonus and tribute of the day, reads see the day as overridden.
+var almanaxJoins = `
 		FROM almanax AS a
-		JOIN bonus AS b ON a.bonus_id = b.id
+		LEFT JOIN overrides AS o ON o.date = a.date AND o.deleted_at IS NULL AND o.expires_at > datetime('now')
+		JOIN bonus AS b ON COALESCE(o.bonus_id, a.bonus_id) = b.id
 		JOIN bonus_types AS bt ON b.bonus_type_id = bt.id
-		JOIN tribute AS t ON a.tribute_id = t.id
-		WHERE a.date >= ? AND a.date <= ? AND bt.name_id = ? AND a.deleted_at IS NULL
-		ORDER BY a.date ASC`
+		JOIN tribute AS t ON COALESCE(o.tribute_id, a.tribute_id) = t.id`
+
+var almanaxSelect = `
+		SELECT
+			a.id, a.bonus_id, a.tribute_id, a.date, a.reward_kamas, a.created_at, a.updated_at, a.deleted_at,
+			b.id, b.bonus_type_id, ` + translationsSelect(TranslationEntityBonus, "b.id") + `,
+			bt.id, bt.name_id, ` + translationsSelect(TranslationEntityBonusType, "bt.id") + `,
+			t.id, ` + translationsSelect(TranslationEntityTribute, "t.id") + `,
+			t.item_icon, t.item_sd, t.item_hq, t.item_hd, t.item_ankama_id, t.item_subtype, t.item_doduapi_uri, t.quantity,
+			o.id, o.bonus_id, o.tribute_id, o.reason, o.expires_at, o.updated_at` + almanaxJoins
+
+func scanMappedAlmanaxRow(rows *sql.Rows, denorm *MappedAlmanax) error {
+	var deletedAt sql.NullTime
+	var override Override
+	var overrideId sql.NullInt64
+	var overrideReason sql.NullString
+	var overrideExpiresAt, overrideUpdatedAt sql.NullTime
+
+	err := rows.Scan(
+		&denorm.Almanax.ID, &denorm.Almanax.BonusID, &denorm.Almanax.TributeID, &denorm.Almanax.Date,
+		&denorm.Almanax.RewardKamas, &denorm.Almanax.CreatedAt, &denorm.Almanax.UpdatedAt, &deletedAt,
+		&denorm.Bonus.ID, &denorm.Bonus.BonusTypeID, &denorm.Bonus.Descriptions,
+		&denorm.BonusType.ID, &denorm.BonusType.NameID, &denorm.BonusType.Names,
+		&denorm.Tribute.ID, &denorm.Tribute.ItemNames,
+		&denorm.Tribute.ItemIcon, &denorm.Tribute.ItemSd, &denorm.Tribute.ItemHq, &denorm.Tribute.ItemHd,
+		&denorm.Tribute.ItemAnkamaID, &denorm.Tribute.ItemSubtype,
+		&denorm.Tribute.ItemDoduapiUri, &denorm.Tribute.Quantity,
+		&overrideId, &override.BonusID, &override.TributeID, &overrideReason, &overrideExpiresAt, &overrideUpdatedAt)
 
-	rows, err := r.Db.Query(query, from, to, nameID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	if deletedAt.Valid {
+		denorm.Almanax.DeletedAt = &deletedAt.Time
+	}
+	if overrideId.Valid {
+		override.ID = overrideId.Int64
+		override.Date = denorm.Almanax.Date
+		override.Reason = overrideReason.String
+		override.ExpiresAt = overrideExpiresAt.Time
+		override.UpdatedAt = overrideUpdatedAt.Time
+		denorm.Override = &override
+	}
+
+	return nil
+}
 
+func scanMappedAlmanax(rows *sql.Rows) ([]MappedAlmanax, error) {
 	var result []MappedAlmanax
 
 	for rows.Next() {
 		var denorm MappedAlmanax
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&denorm.Almanax.ID, &denorm.Almanax.BonusID, &denorm.Almanax.TributeID, &denorm.Almanax.Date,
-			&denorm.Almanax.RewardKamas, &denorm.Almanax.CreatedAt, &denorm.Almanax.UpdatedAt, &deletedAt,
-			&denorm.Bonus.ID, &denorm.Bonus.BonusTypeID, &denorm.Bonus.DescriptionEn, &denorm.Bonus.DescriptionFr,
-			&denorm.Bonus.DescriptionEs, &denorm.Bonus.DescriptionDe, &denorm.Bonus.DescriptionIt, &denorm.Bonus.DescriptionPt,
-			&denorm.BonusType.ID, &denorm.BonusType.NameID, &denorm.BonusType.NameEn, &denorm.BonusType.NameFr,
-			&denorm.BonusType.NameEs, &denorm.BonusType.NameDe, &denorm.BonusType.NameIt, &denorm.BonusType.NamePt,
-			&denorm.Tribute.ID, &denorm.Tribute.ItemNameEn, &denorm.Tribute.ItemNameFr, &denorm.Tribute.ItemNameEs, &denorm.Tribute.ItemNameDe, &denorm.Tribute.ItemNameIt, &denorm.Tribute.ItemNamePt,
-			&denorm.Tribute.ItemIcon, &denorm.Tribute.ItemSd, &denorm.Tribute.ItemHq, &denorm.Tribute.ItemHd,
-			&denorm.Tribute.ItemAnkamaID, &denorm.Tribute.ItemSubtype,
-			&denorm.Tribute.ItemDoduapiUri, &denorm.Tribute.Quantity)
-
-		if err != nil {
+		if err := scanMappedAlmanaxRow(rows, &denorm); err != nil {
 			return nil, err
 		}
-		if deletedAt.Valid {
-			denorm.Almanax.DeletedAt = &deletedAt.Time
-		}
-
 		result = append(result, denorm)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
-func (r *Repository) GetAlmanaxByDateRange(from, to string) ([]MappedAlmanax, error) {
-	query := `
-		SELECT
-			a.id, a.bonus_id, a.tribute_id, a.date, a.reward_kamas, a.created_at, a.updated_at, a.deleted_at,
-			b.id, b.bonus_type_id, b.description_en, b.description_fr, b.description_es, b.description_de, b.description_it, b.description_pt,
-			bt.id, bt.name_id, bt.name_en, bt.name_fr, bt.name_es, bt.name_de, bt.name_it, bt.name_pt,
-			t.id, t.item_name_en, t.item_name_fr, t.item_name_es, t.item_name_de, t.item_name_it, t.item_name_pt,
-			t.item_icon, t.item_sd, t.item_hq, t.item_hd, t.item_ankama_id, t.item_subtype, t.item_doduapi_uri, t.quantity
-		FROM almanax AS a
-		JOIN bonus AS b ON a.bonus_id = b.id
-		JOIN bonus_types AS bt ON b.bonus_type_id = bt.id
-		JOIN tribute AS t ON a.tribute_id = t.id
+func (r *Repository) GetAlmanaxByDateRangeAndNameID(ctx context.Context, from, to, nameID string) ([]MappedAlmanax, error) {
+	defer observeQuery(ctx, "almanax_by_date_range_and_name_id")()
+
+	query := almanaxSelect + `
+		WHERE a.date >= ? AND a.date <= ? AND bt.name_id = ? AND a.deleted_at IS NULL
+		ORDER BY a.date ASC`
+
+	rows, err := r.Db.QueryContext(ctx, query, from, to, nameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMappedAlmanax(rows)
+}
+
+func (r *Repository) GetAlmanaxByDateRange(ctx context.Context, from, to string) ([]MappedAlmanax, error) {
+	defer observeQuery(ctx, "almanax_by_date_range")()
+
+	query := almanaxSelect + `
 		WHERE a.date >= ? AND a.date <= ? AND a.deleted_at IS NULL
 		ORDER BY a.date ASC`
 
-	rows, err := r.Db.Query(query, from, to)
+	rows, err := r.Db.QueryContext(ctx, query, from, to)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []MappedAlmanax
+	return scanMappedAlmanax(rows)
+}
+
+// GetAlmanaxByDates returns the given days sorted by date, unknown dates are skipped.
+func (r *Repository) GetAlmanaxByDates(ctx context.Context, dates []string) ([]MappedAlmanax, error) {
+	defer observeQuery(ctx, "almanax_by_dates")()
+
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(dates))
+	for i := range dates {
+		args[i] = dates[i]
+	}
+
+	query := almanaxSelect + `
+		WHERE a.date IN (?` + strings.Repeat(", ?", len(dates)-1) + `) AND a.deleted_at IS NULL
+		ORDER BY a.date ASC`
+
+	rows, err := r.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMappedAlmanax(rows)
+}
+
+// CountAlmanaxByDateRange counts the almanax days in the range. An empty nameID disables the bonus type filter.
+func (r *Repository) CountAlmanaxByDateRange(ctx context.Context, from, to, nameID string) (int, error) {
+	defer observeQuery(ctx, "count_almanax_by_date_range")()
+
+	query := `
+		SELECT COUNT(*)` + almanaxJoins + `
+		WHERE a.date >= ? AND a.date <= ? AND (? = '' OR bt.name_id = ?) AND a.deleted_at IS NULL`
+
+	var count int
+	err := r.Db.QueryRowContext(ctx, query, from, to, nameID, nameID).Scan(&count)
+	return count, err
+}
+
+// GetAlmanaxPageByDateRange returns one page of almanax days in the range. An empty nameID disables the bonus type filter.
+func (r *Repository) GetAlmanaxPageByDateRange(ctx context.Context, from, to, nameID string, limit, offset int) ([]MappedAlmanax, error) {
+	defer observeQuery(ctx, "almanax_page_by_date_range")()
+
+	query := almanaxSelect + `
+		WHERE a.date >= ? AND a.date <= ? AND (? = '' OR bt.name_id = ?) AND a.deleted_at IS NULL
+		ORDER BY a.date ASC
+		LIMIT ? OFFSET ?`
+
+	rows, err := r.Db.QueryContext(ctx, query, from, to, nameID, nameID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMappedAlmanax(rows)
+}
+
+// StreamAlmanaxByDateRange calls fn for every almanax day in the range without holding the result in memory.
+// An empty nameID disables the bonus type filter.
+func (r *Repository) StreamAlmanaxByDateRange(ctx context.Context, from, to, nameID string, fn func(*MappedAlmanax) error) error {
+	defer observeQuery(ctx, "stream_almanax_by_date_range")()
+
+	query := almanaxSelect + `
+		WHERE a.date >= ? AND a.date <= ? AND (? = '' OR bt.name_id = ?) AND a.deleted_at IS NULL
+		ORDER BY a.date ASC`
+
+	rows, err := r.Db.QueryContext(ctx, query, from, to, nameID, nameID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var denorm MappedAlmanax
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&denorm.Almanax.ID, &denorm.Almanax.BonusID, &denorm.Almanax.TributeID, &denorm.Almanax.Date,
-			&denorm.Almanax.RewardKamas, &denorm.Almanax.CreatedAt, &denorm.Almanax.UpdatedAt, &deletedAt,
-			&denorm.Bonus.ID, &denorm.Bonus.BonusTypeID, &denorm.Bonus.DescriptionEn, &denorm.Bonus.DescriptionFr,
-			&denorm.Bonus.DescriptionEs, &denorm.Bonus.DescriptionDe, &denorm.Bonus.DescriptionIt, &denorm.Bonus.DescriptionPt,
-			&denorm.BonusType.ID, &denorm.BonusType.NameID, &denorm.BonusType.NameEn, &denorm.BonusType.NameFr,
-			&denorm.BonusType.NameEs, &denorm.BonusType.NameDe, &denorm.BonusType.NameIt, &denorm.BonusType.NamePt,
-			&denorm.Tribute.ID, &denorm.Tribute.ItemNameEn, &denorm.Tribute.ItemNameFr, &denorm.Tribute.ItemNameEs, &denorm.Tribute.ItemNameDe, &denorm.Tribute.ItemNameIt, &denorm.Tribute.ItemNamePt,
-			&denorm.Tribute.ItemIcon, &denorm.Tribute.ItemSd, &denorm.Tribute.ItemHq, &denorm.Tribute.ItemHd,
-			&denorm.Tribute.ItemAnkamaID, &denorm.Tribute.ItemSubtype,
-			&denorm.Tribute.ItemDoduapiUri, &denorm.Tribute.Quantity)
+		if err = scanMappedAlmanaxRow(rows, &denorm); err != nil {
+			return err
+		}
+		if err = fn(&denorm); err != nil {
+			return err
+		}
+	}
 
-		if err != nil {
+	return rows.Err()
+}
+
+func (r *Repository) GetAlmanaxDates(ctx context.Context) ([]string, error) {
+	defer observeQuery(ctx, "almanax_dates")()
+
+	rows, err := r.Db.QueryContext(ctx, `SELECT date FROM almanax WHERE deleted_at IS NULL ORDER BY date ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []string
+	for rows.Next() {
+		var date string
+		if err = rows.Scan(&date); err != nil {
 			return nil, err
 		}
-		if deletedAt.Valid {
-			denorm.Almanax.DeletedAt = &deletedAt.Time
-		}
+		dates = append(dates, date)
+	}
 
-		result = append(result, denorm)
+	return dates, rows.Err()
+}
+
+// GetMigrationVersion returns the schema version golang-migrate recorded and whether the last migration failed halfway.
+func (r *Repository) GetMigrationVersion(ctx context.Context) (uint, bool, error) {
+	defer observeQuery(ctx, "migration_version")()
+
+	var version uint
+	var dirty bool
+	err := r.Db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
 	}
+	return version, dirty, err
+}
 
-	if err = rows.Err(); err != nil {
+// GetLatestImport returns the most recent import or nil if the database was never imported into.
+func (r *Repository) GetLatestImport(ctx context.Context) (*Import, error) {
+	defer observeQuery(ctx, "latest_import")()
+
+	query := `
+		SELECT id, release_tag, requested_version, asset_id, downloaded_at, received, inserted, updated, unchanged, kept, skipped_past, overrides_agreed, overrides_disagreed, dodualm_version, imported_at
+		FROM imports
+		ORDER BY imported_at DESC, id DESC
+		LIMIT 1`
+
+	var imp Import
+	err := r.Db.QueryRowContext(ctx, query).Scan(&imp.ID, &imp.ReleaseTag, &imp.RequestedVersion, &imp.AssetId, &imp.DownloadedAt,
+		&imp.Received, &imp.Inserted, &imp.Updated, &imp.Unchanged, &imp.Kept, &imp.SkippedPast, &imp.OverridesAgreed, &imp.OverridesDisagreed, &imp.DodualmVersion, &imp.ImportedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return &imp, nil
 }
 
-func (r *Repository) Create(almanax *Almanax) (int64, error) {
+// GetLatestEdit returns the time of the latest manual edit or override change as stored, empty if nothing was edited.
+func (r *Repository) GetLatestEdit(ctx context.Context) (string, error) {
+	defer observeQuery(ctx, "latest_edit")()
+
 	query := `
-		INSERT INTO almanax (bonus_id, tribute_id, date, reward_kamas, created_at, updated_at)
-		VALUES (?, ?, ?, ?, datetime('now'), datetime('now'))`
-	result, err := r.Db.Exec(query, almanax.BonusID, almanax.TributeID, almanax.Date, almanax.RewardKamas)
+		SELECT MAX(edited_at) FROM (
+			SELECT MAX(edited_at) AS edited_at FROM almanax
+			UNION ALL SELECT MAX(edited_at) FROM bonus
+			UNION ALL SELECT MAX(edited_at) FROM bonus_types
+			UNION ALL SELECT MAX(edited_at) FROM tribute
+			UNION ALL SELECT MAX(edited_at) FROM overrides)`
+
+	var latest sql.NullString
+	if err := r.Db.QueryRowContext(ctx, query).Scan(&latest); err != nil {
+		return "", err
+	}
+	return latest.String, nil
+}
+
+func (r *Repository) Create(ctx context.Context, almanax *Almanax) (int64, error) {
+	var id int64
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		id, err = createAlmanax(tx, almanax)
+		return err
+	})
+	return id, err
+}
+
+func (r *Repository) UpdateAlmanax(ctx context.Context, almanax *Almanax) error {
+	return r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		return updateAlmanax(tx, almanax)
+	})
+}
+
+func (r *Repository) CreateBonusType(ctx context.Context, bonusType *BonusType) (int64, error) {
+	var id int64
+	err := r.WithTxContext(ctx, func(tx *sql.Tx) error {
+		var err error
+		id, err = createBonusType(tx, bonusType)
+		return err
+	})
+	return id, err
+}
+
+// editedAtSql sets edited_at to now for manual edits, the argument is the EditedBy of the row.
+const editedAtSql = `CASE WHEN ? != '' THEN datetime('now') END`
+
+func createAlmanax(tx *sql.Tx, almanax *Almanax) (int64, error) {
+	query := `
+		INSERT INTO almanax (bonus_id, tribute_id, date, reward_kamas, edited_by, edited_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ` + editedAtSql + `, datetime('now'), datetime('now'))`
+	result, err := tx.Exec(query, almanax.BonusID, almanax.TributeID, almanax.Date, almanax.RewardKamas, almanax.EditedBy, almanax.EditedBy)
 	if err != nil {
 		return 0, err
 	}
 	return result.LastInsertId()
 }
 
-func (r *Repository) UpdateAlmanax(almanax *Almanax) error {
+func updateAlmanax(tx *sql.Tx, almanax *Almanax) error {
 	query := `
 		UPDATE almanax
-		SET bonus_id = ?, tribute_id = ?, date = ?, reward_kamas = ?, updated_at = datetime('now')
+		SET bonus_id = ?, tribute_id = ?, date = ?, reward_kamas = ?, deleted_at = NULL,
+			edited_by = ?, edited_at = ` + editedAtSql + `, updated_at = datetime('now')
 		WHERE id = ?`
-	_, err := r.Db.Exec(query, almanax.BonusID, almanax.TributeID, almanax.Date, almanax.RewardKamas, almanax.ID)
+	_, err := tx.Exec(query, almanax.BonusID, almanax.TributeID, almanax.Date, almanax.RewardKamas,
+		almanax.EditedBy, almanax.EditedBy, almanax.ID)
 	return err
 }
 
-func (r *Repository) CreateBonusType(bonusType *BonusType) (int64, error) {
-	query := `INSERT INTO bonus_types (name_id, name_en, name_fr, name_es, name_de, name_it, name_pt, created_at, updated_at)
-	          VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))`
-	result, err := r.Db.Exec(query, bonusType.NameID, bonusType.NameEn, bonusType.NameFr, bonusType.NameEs,
-		bonusType.NameDe, bonusType.NameIt, bonusType.NamePt)
+func createBonusType(tx *sql.Tx, bonusType *BonusType) (int64, error) {
+	query := `INSERT INTO bonus_types (name_id, edited_by, edited_at, created_at, updated_at)
+	          VALUES (?, ?, ` + editedAtSql + `, datetime('now'), datetime('now'))`
+	result, err := tx.Exec(query, bonusType.NameID, bonusType.EditedBy, bonusType.EditedBy)
 	if err != nil {
 		return 0, err
 	}
-	return result.LastInsertId()
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, upsertTranslations(tx, TranslationEntityBonusType, id, bonusType.Names)
+}
+
+func createBonus(tx *sql.Tx, bonus *Bonus) (int64, error) {
+	query := `INSERT INTO bonus (bonus_type_id, edited_by, edited_at, created_at, updated_at)
+	          VALUES (?, ?, ` + editedAtSql + `, datetime('now'), datetime('now'))`
+	result, err := tx.Exec(query, bonus.BonusTypeID, bonus.EditedBy, bonus.EditedBy)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, upsertTranslations(tx, TranslationEntityBonus, id, bonus.Descriptions)
+}
+
+func createTribute(tx *sql.Tx, tribute *Tribute) (int64, error) {
+	query := `INSERT INTO tribute (item_icon, item_sd, item_hq, item_hd, item_ankama_id, item_subtype, item_doduapi_uri, quantity,
+	              edited_by, edited_at, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ` + editedAtSql + `, datetime('now'), datetime('now'))`
+	result, err := tx.Exec(query, tribute.ItemIcon, tribute.ItemSd, tribute.ItemHq, tribute.ItemHd,
+		tribute.ItemAnkamaID, tribute.ItemSubtype, tribute.ItemDoduapiUri, tribute.Quantity, tribute.EditedBy, tribute.EditedBy)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return id, upsertTranslations(tx, TranslationEntityTribute, id, tribute.ItemNames)
+}
+
+func (r *Repository) GetBonusTypes(ctx context.Context) ([]BonusType, error) {
+	defer observeQuery(ctx, "bonus_types")()
+
+	query := `
+		SELECT id, name_id, ` + translationsSelect(TranslationEntityBonusType, "bonus_types.id") + `, created_at, updated_at
+		FROM bonus_types
+		WHERE deleted_at IS NULL
+		ORDER BY name_id ASC`
+
+	rows, err := r.Db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []BonusType
+
+	for rows.Next() {
+		var bonusType BonusType
+		err := rows.Scan(&bonusType.ID, &bonusType.NameID, &bonusType.Names, &bonusType.CreatedAt, &bonusType.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, bonusType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate"
+	"github.com/golang-migrate/migrate/database/sqlite3"
+	"github.com/golang-migrate/migrate/source/file"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRepository opens a database in a temporary directory with all migrations applied.
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	dir := t.TempDir()
+	repo := &Repository{}
+	if err := repo.Init(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(repo.Deinit)
+
+	dbDriver, err := sqlite3.WithInstance(repo.Writer, &sqlite3.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileSource, err := (&file.File{}).Open("file://migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.NewWithInstance("file", fileSource, "myDB", dbDriver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// useTestDatabase points the handlers at repo until the test ends.
+func useTestDatabase(t *testing.T, repo *Repository) {
+	database, cache := Database, Cache
+	coverageMu.Lock()
+	coverage := cachedCoverage
+	cachedCoverage = nil
+	coverageMu.Unlock()
+	t.Cleanup(func() {
+		Database, Cache = database, cache
+		coverageMu.Lock()
+		cachedCoverage = coverage
+		coverageMu.Unlock()
+	})
+	Database = repo
+	Cache = NewAlmanaxCache(repo, 0, 0)
+}
+
+func countBonusTypes(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var count int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM bonus_types`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestRepositoryWal(t *testing.T) {
+	repo := newTestRepository(t)
+
+	var mode string
+	assert.NoError(t, repo.Db.QueryRow(`PRAGMA journal_mode`).Scan(&mode))
+	assert.Equal(t, "wal", mode)
+
+	_, err := repo.Db.Exec(`INSERT INTO bonus_types (name_id) VALUES ('reader')`)
+	assert.Error(t, err, "the reader pool is query only")
+}
+
+func TestRepositoryReadDuringWrite(t *testing.T) {
+	repo := newTestRepository(t)
+
+	err := repo.WithTxContext(context.Background(), func(tx *sql.Tx) error {
+		if _, err := createBonusType(tx, &BonusType{NameID: "pending"}); err != nil {
+			return err
+		}
+		// the open write transaction neither blocks readers nor shows them its changes
+		assert.Equal(t, 0, countBonusTypes(t, repo.Db))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countBonusTypes(t, repo.Db))
+}
+
+func TestRepositoryImmediateLock(t *testing.T) {
+	repo := newTestRepository(t)
+
+	var dbpath string
+	assert.NoError(t, repo.Db.QueryRow(`SELECT file FROM pragma_database_list WHERE name = 'main'`).Scan(&dbpath))
+	other, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=0&_txlock=immediate", dbpath))
+	assert.NoError(t, err)
+	defer other.Close()
+
+	err = repo.WithTxContext(context.Background(), func(tx *sql.Tx) error {
+		// nothing was written yet, the lock is taken when the transaction begins
+		_, err := other.Begin()
+		assert.Error(t, err, "a second writer has to wait for the write lock")
+		return nil
+	})
+	assert.NoError(t, err)
+
+	tx, err := other.Begin()
+	assert.NoError(t, err, "the lock is released on commit")
+	if tx != nil {
+		tx.Rollback()
+	}
+}
+
+func TestRepositorySerializesWrites(t *testing.T) {
+	repo := newTestRepository(t)
+
+	var active, maxActive atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.WithTxContext(context.Background(), func(tx *sql.Tx) error {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for {
+					m := maxActive.Load()
+					if n <= m || maxActive.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				_, err := createBonusType(tx, &BonusType{NameID: fmt.Sprint("type-", i)})
+				return err
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), maxActive.Load())
+	assert.Equal(t, 8, countBonusTypes(t, repo.Db))
+}
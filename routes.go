@@ -23,13 +23,35 @@ func dateExtractMiddleware(next http.Handler) http.Handler {
 func languageChecker(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		lang := strings.ToLower(chi.URLParam(r, "lang"))
-		switch lang {
-		case "en", "fr", "de", "es", "pt":
-			ctx := context.WithValue(r.Context(), "lang", lang)
-			next.ServeHTTP(w, r.WithContext(ctx))
-		default:
-			w.WriteHeader(http.StatusBadRequest)
+		if !sliceContains(Languages, lang) {
+			writeInvalidLanguageResponse(w, lang)
+			return
 		}
+		w.Header().Set("Content-Language", lang)
+		ctx := context.WithValue(r.Context(), "lang", lang)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// languageNegotiator selects the language from Accept-Language for the routes without a {lang} segment.
+func languageNegotiator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := negotiateLanguage(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", lang)
+		ctx := context.WithValue(r.Context(), "lang", lang)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// releaseHeader names the dofus3-main release of the served data, see /meta/almanax/info for details.
+func releaseHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tag := DatasetTag(); tag != "" {
+			w.Header().Set(ReleaseHeader, tag)
+			w.Header().Add("Access-Control-Expose-Headers", ReleaseHeader)
+		}
+		next.ServeHTTP(w, r)
 	})
 }
 
@@ -41,24 +63,106 @@ func useCors(next http.Handler) http.Handler {
 	})
 }
 
+const (
+	requestTimeout = 10 * time.Second
+	exportTimeout  = 10 * time.Minute
+)
+
 func Router() chi.Router {
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
+	if TrustProxyHeaders {
+		r.Use(middleware.RealIP)
+	}
+	r.Use(middleware.RequestID)
+	r.Use(tracingMiddleware)
+	r.Use(requestLogger)
+	r.Use(metricsMiddleware)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Default().Handler)
-	r.Use(middleware.Timeout(10 * time.Second))
 
 	dofusdudeApiMajor := 1
 
-	r.With(useCors).With(languageChecker).Route(fmt.Sprintf("/dofus3/v%d", dofusdudeApiMajor), func(r chi.Router) {
-		r.Route("/meta/{lang}/almanax/bonuses", func(r chi.Router) {
-			r.Get("/", ListBonuses)
-			r.Get("/search", SearchBonuses)
+	r.With(useCors).Route(fmt.Sprintf("/dofus3/v%d", dofusdudeApiMajor), func(r chi.Router) {
+		// exports stream the whole requested range, the request timeout would cut them off mid-body
+		r.Group(func(r chi.Router) {
+			r.Use(rateLimit)
+			r.Use(releaseHeader)
+			r.Use(middleware.Timeout(exportTimeout))
+			r.With(languageChecker).Get("/{lang}/almanax/export", ExportAlmanax)
+			r.With(languageNegotiator).Get("/almanax/export", ExportAlmanax)
 		})
 
-		r.Route("/{lang}/almanax", func(r chi.Router) {
-			r.Get("/", RetrieveAlmanax)
-			r.With(languageChecker).Put("/{lang}", UpdateAlmanax)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(requestTimeout))
+			r.Get("/openapi.json", RetrieveOpenApi)
+			r.Get("/docs", RetrieveApiDocs)
+			r.Get("/healthz", RetrieveHealth)
+			r.Get("/readyz", RetrieveReadiness)
+			// the documentation and the probes are not rate limited, orchestrators poll the probes from a few addresses
+			r.Group(func(r chi.Router) {
+				r.Use(rateLimit)
+				r.Get("/meta/almanax/info", RetrieveAlmanaxInfo)
+
+				// every localized route exists with a {lang} segment and without one, negotiating from Accept-Language
+				bonusRoutes := func(r chi.Router) {
+					r.Use(releaseHeader)
+					r.Get("/", ListBonuses)
+					r.Get("/search", SearchBonuses)
+				}
+				almanaxRoutes := func(r chi.Router) {
+					r.Use(releaseHeader)
+					r.Get("/", RetrieveAlmanax)
+					r.Get("/ics", RetrieveAlmanaxIcs)
+					r.Get("/rss", RetrieveAlmanaxRss)
+					r.Get("/search", SearchAlmanax)
+					r.With(dateExtractMiddleware).Get("/{date}", RetrieveAlmanaxDate)
+				}
+
+				r.Route("/meta/{lang}/almanax/bonuses", func(r chi.Router) {
+					r.Use(languageChecker)
+					bonusRoutes(r)
+				})
+
+				r.Route("/meta/almanax/bonuses", func(r chi.Router) {
+					r.Use(languageNegotiator)
+					bonusRoutes(r)
+				})
+
+				r.Route("/{lang}/almanax", func(r chi.Router) {
+					r.Use(languageChecker)
+					almanaxRoutes(r)
+					r.With(languageChecker).Put("/{lang}", UpdateAlmanax)
+				})
+
+				r.Route("/almanax", func(r chi.Router) {
+					r.Get("/coverage", RetrieveAlmanaxCoverage)
+					r.Group(func(r chi.Router) {
+						r.Use(languageNegotiator)
+						almanaxRoutes(r)
+					})
+				})
+
+				// manual corrections, the author of an edit is the name of the admin token
+				r.Route("/admin", func(r chi.Router) {
+					r.Use(adminAuth)
+					r.Post("/almanax", CreateAdminAlmanax)
+					r.Put("/almanax/{date}", UpdateAdminAlmanax)
+					r.Delete("/almanax/{date}", DeleteAdminAlmanax)
+					r.Post("/bonus-types", CreateAdminBonusType)
+					r.Put("/bonus-types/{id}", UpdateAdminBonusType)
+					r.Delete("/bonus-types/{id}", DeleteAdminBonusType)
+					r.Post("/bonuses", CreateAdminBonus)
+					r.Put("/bonuses/{id}", UpdateAdminBonus)
+					r.Delete("/bonuses/{id}", DeleteAdminBonus)
+					r.Post("/tributes", CreateAdminTribute)
+					r.Put("/tributes/{id}", UpdateAdminTribute)
+					r.Delete("/tributes/{id}", DeleteAdminTribute)
+					r.Get("/overrides", ListAdminOverrides)
+					r.Post("/overrides", CreateAdminOverride)
+					r.Put("/overrides/{id}", UpdateAdminOverride)
+					r.Delete("/overrides/{id}", DeleteAdminOverride)
+				})
+			})
 		})
 	})
 
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+var ErrSearchUnavailable = errors.New("search is unavailable")
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "closed"
+}
+
+// CircuitBreaker opens after FailureThreshold consecutive failures and rejects calls for OpenTimeout.
+// Afterwards a single probe call is let through, its result closes or reopens the breaker.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	FailureThreshold int
+	OpenTimeout      time.Duration
+
+	now           func() time.Time
+	onStateChange func(BreakerState)
+}
+
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		OpenTimeout:      openTimeout,
+		now:              time.Now,
+		onStateChange:    func(BreakerState) {},
+	}
+}
+
+func (b *CircuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	log.Warn("search circuit breaker changed state", "from", b.state, "to", state)
+	b.state = state
+	b.onStateChange(state)
+}
+
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow reports if a call may be made. Every allowed call must be followed by Success or Failure.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.OpenTimeout {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.setState(BreakerClosed)
+}
+
+// Cancel releases an allowed call that ended without telling anything about the health of the service.
+func (b *CircuitBreaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.FailureThreshold {
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+	}
+}
+
+type searchCacheEntry struct {
+	response *meilisearch.SearchResponse
+	storedAt time.Time
+}
+
+// SearchClient is the shared Meilisearch client. It keeps recent results for ResultTTL and serves them up to
+// StaleTTL when Meilisearch fails or the breaker is open.
+type SearchClient struct {
+	meili   meilisearch.ServiceManager
+	breaker *CircuitBreaker
+	Timeout time.Duration
+
+	mu         sync.Mutex
+	results    map[string]searchCacheEntry
+	ResultTTL  time.Duration
+	StaleTTL   time.Duration
+	MaxResults int
+
+	now func() time.Time
+}
+
+var Search *SearchClient
+
+func NewSearchClient(host, key string, timeout time.Duration) *SearchClient {
+	httpClient := &http.Client{
+		Timeout: timeout,
+		Transport: meiliTransport{next: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			DialContext:         (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext,
+			MaxIdleConns:        64,
+			MaxIdleConnsPerHost: 64,
+			IdleConnTimeout:     90 * time.Second,
+		}},
+	}
+
+	breaker := NewCircuitBreaker(5, 30*time.Second)
+	breaker.onStateChange = func(state BreakerState) {
+		searchBreakerState.Set(float64(state))
+	}
+
+	return &SearchClient{
+		// retries would only pile up on a struggling instance, the breaker handles failures
+		meili:      meilisearch.New(host, meilisearch.WithAPIKey(key), meilisearch.WithCustomClient(httpClient), meilisearch.DisableRetries()),
+		breaker:    breaker,
+		Timeout:    timeout,
+		results:    make(map[string]searchCacheEntry),
+		ResultTTL:  30 * time.Second,
+		StaleTTL:   10 * time.Minute,
+		MaxResults: 2048,
+		now:        time.Now,
+	}
+}
+
+func (c *SearchClient) Close() {
+	c.meili.Close()
+}
+
+func searchCacheKey(index, query string, request *meilisearch.SearchRequest) string {
+	encoded, _ := json.Marshal(request)
+	return index + "\x00" + query + "\x00" + string(encoded)
+}
+
+func (c *SearchClient) cached(key string, maxAge time.Duration) (*meilisearch.SearchResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.results[key]
+	if !ok || c.now().Sub(entry.storedAt) > maxAge {
+		return nil, false
+	}
+	return entry.response, true
+}
+
+func (c *SearchClient) store(key string, response *meilisearch.SearchResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.results) >= c.MaxResults {
+		for k, entry := range c.results {
+			if c.now().Sub(entry.storedAt) > c.StaleTTL {
+				delete(c.results, k)
+			}
+		}
+		for k := range c.results { // still full, drop arbitrary entries
+			if len(c.results) < c.MaxResults {
+				break
+			}
+			delete(c.results, k)
+		}
+	}
+
+	c.results[key] = searchCacheEntry{response: response, storedAt: c.now()}
+}
+
+// isSearchFailure tells errors of an unhealthy instance apart from rejected requests like a missing index.
+func isSearchFailure(err error) bool {
+	var meiliErr *meilisearch.Error
+	if errors.As(err, &meiliErr) && meiliErr.StatusCode >= 400 && meiliErr.StatusCode < 500 {
+		return false
+	}
+	return true
+}
+
+// Search queries the index. The second return value is true when a stale result is served because
+// Meilisearch is unavailable. ErrSearchUnavailable is returned if there is no stale result either.
+func (c *SearchClient) Search(ctx context.Context, index, query string, request *meilisearch.SearchRequest) (*meilisearch.SearchResponse, bool, error) {
+	key := searchCacheKey(index, query, request)
+	if response, ok := c.cached(key, c.ResultTTL); ok {
+		searchCacheHitsTotal.Inc()
+		return response, false, nil
+	}
+	searchCacheMissesTotal.Inc()
+
+	stale := func(cause error) (*meilisearch.SearchResponse, bool, error) {
+		if response, ok := c.cached(key, c.StaleTTL); ok {
+			searchStaleTotal.Inc()
+			return response, true, nil
+		}
+		return nil, false, errors.Join(ErrSearchUnavailable, cause)
+	}
+
+	if !c.breaker.Allow() {
+		return stale(errors.New("circuit breaker is open"))
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	searchRequest := *request // the client fills in defaults, keep the cache key stable
+	response, err := c.meili.Index(index).SearchWithContext(searchCtx, query, &searchRequest)
+	if err != nil {
+		if ctx.Err() != nil { // the client went away
+			c.breaker.Cancel()
+			return nil, false, ctx.Err()
+		}
+		if !isSearchFailure(err) {
+			c.breaker.Success()
+			return nil, false, err
+		}
+		c.breaker.Failure()
+		log.FromContext(ctx).Warn("search failed", "index", index, "err", err)
+		return stale(err)
+	}
+
+	c.breaker.Success()
+	c.store(key, response)
+	return response, false, nil
+}
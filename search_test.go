@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	assert.True(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, BreakerClosed, b.State())
+	assert.True(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.False(t, b.Allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow())
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.False(t, b.Allow(), "only one probe at a time")
+	b.Failure()
+	assert.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow())
+	b.Success()
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestSearchClientServesStale(t *testing.T) {
+	var failing atomic.Bool
+	var calls atomic.Int32
+	meili := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"hits":[{"slug":"harvest","name":"Harvest"}],"estimatedTotalHits":1,"query":"harv"}`))
+	}))
+	defer meili.Close()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewSearchClient(meili.URL, "", time.Second)
+	c.now = func() time.Time { return now }
+	c.breaker.FailureThreshold = 1
+	defer c.Close()
+
+	request := &meilisearch.SearchRequest{Limit: 8}
+	res, stale, err := c.Search(context.Background(), "alm-bonuses-en", "harv", request)
+	assert.NoError(t, err)
+	assert.False(t, stale)
+	assert.Len(t, res.Hits, 1)
+
+	c.Search(context.Background(), "alm-bonuses-en", "harv", request)
+	assert.Equal(t, int32(1), calls.Load(), "recent results come from the cache")
+
+	failing.Store(true)
+	now = now.Add(time.Minute)
+	res, stale, err = c.Search(context.Background(), "alm-bonuses-en", "harv", request)
+	assert.NoError(t, err)
+	assert.True(t, stale)
+	assert.Len(t, res.Hits, 1)
+	assert.Equal(t, BreakerOpen, c.breaker.State())
+
+	_, _, err = c.Search(context.Background(), "alm-bonuses-en", "other", request)
+	assert.ErrorIs(t, err, ErrSearchUnavailable)
+	assert.Equal(t, int32(2), calls.Load(), "no calls while the breaker is open")
+}
+
+// useTestSearch points the handlers at a fake Meilisearch that answers with handler until the test ends.
+func useTestSearch(t *testing.T, handler http.HandlerFunc) {
+	meili := httptest.NewServer(handler)
+	client := NewSearchClient(meili.URL, "", time.Second)
+	client.breaker.FailureThreshold = 1
+	search := Search
+	t.Cleanup(func() {
+		Search = search
+		client.Close()
+		meili.Close()
+	})
+	Search = client
+}
+
+func TestSearchAlmanax(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	_, err := repo.ImportAlmanax(context.Background(), []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 2, "2030-01-01"),
+		testMappedAlmanax("Harvest", "More crops", 7, 5, "2030-01-02", "2030-01-03"),
+	}, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01")
+	assert.NoError(t, err)
+
+	var searchedPath string
+	var searched map[string]interface{}
+	useTestSearch(t, func(w http.ResponseWriter, r *http.Request) {
+		searchedPath = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &searched)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"hits":[{"date":"2030-01-02"},{"date":"2030-01-03"},{"name":"no date"}],"query":"crops",` +
+			`"page":1,"hitsPerPage":2,"totalPages":2,"totalHits":3,` +
+			`"facetDistribution":{"bonus_type_id":{"harvest":2,"experience":1},"item_subtype":{"Resource":3}}}`))
+	})
+
+	rec := getTestRoute(t, "/dofus3/v1/en/almanax/search?query=crops&page[size]=2&filter[bonus.type_name]=harvest")
+	assert.Equal(t, "/indexes/alm-days-en/search", searchedPath)
+	assert.Equal(t, "crops", searched["q"])
+	assert.Equal(t, 2.0, searched["hitsPerPage"])
+	assert.Equal(t, []interface{}{`bonus_type_id = "harvest"`}, searched["filter"])
+
+	var res AlmanaxSearchResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, 3, res.Total)
+	if assert.Len(t, res.Items, 2, "hits without a date are skipped") {
+		assert.Equal(t, "2030-01-02", res.Items[0].Date)
+		assert.Equal(t, "More crops", res.Items[0].Bonus.Description)
+		assert.Equal(t, "2030-01-03", res.Items[1].Date)
+	}
+	assert.Equal(t, map[string]int{"harvest": 2, "experience": 1}, res.Facets.BonusType)
+	assert.Equal(t, map[string]int{"Resource": 3}, res.Facets.ItemSubtype)
+	assert.Nil(t, res.Links.Prev)
+	if assert.NotNil(t, res.Links.Next) {
+		assert.Contains(t, *res.Links.Next, "page%5Bnumber%5D=2")
+		assert.Contains(t, *res.Links.Next, "query=crops")
+	}
+	assert.Equal(t, *res.Links.Next, res.Links.Last)
+}
+
+func TestSearchAlmanaxWithoutFacets(t *testing.T) {
+	useTestDatabase(t, newTestRepository(t))
+	useTestSearch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"hits":[],"query":"nothing","page":1,"hitsPerPage":20,"totalPages":0,"totalHits":0}`))
+	})
+
+	rec := getTestRoute(t, "/dofus3/v1/en/almanax/search?query=nothing")
+	var res map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, []interface{}{}, res["items"])
+	assert.Equal(t, map[string]interface{}{"bonus_type": map[string]interface{}{}, "item_subtype": map[string]interface{}{}}, res["facets"])
+	assert.Nil(t, res["_links"].(map[string]interface{})["next"])
+}
+
+func TestSearchAlmanaxErrors(t *testing.T) {
+	useTestDatabase(t, newTestRepository(t))
+	status := http.StatusBadRequest
+	useTestSearch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message":"invalid filter","code":"invalid_search_filter","type":"invalid_request","link":""}`))
+	})
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		code   string
+	}{
+		{name: "missing query", url: "/dofus3/v1/en/almanax/search", status: http.StatusBadRequest, code: ERR_INVALID_QUERY_VALUE},
+		{name: "invalid page", url: "/dofus3/v1/en/almanax/search?query=x&page[number]=0", status: http.StatusBadRequest, code: ERR_INVALID_QUERY_VALUE},
+		{name: "invalid future filter", url: "/dofus3/v1/en/almanax/search?query=x&filter[future]=maybe", status: http.StatusBadRequest, code: ERR_INVALID_FILTER_VALUE},
+		{name: "rejected by meilisearch", url: "/dofus3/v1/en/almanax/search?query=x", status: http.StatusInternalServerError, code: ERR_SERVER_ERROR},
+		// runs last, the failure opens the breaker
+		{name: "meilisearch down", url: "/dofus3/v1/en/almanax/search?query=y", status: http.StatusServiceUnavailable, code: ERR_SERVICE_UNAVAILABLE},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.status == http.StatusServiceUnavailable {
+				status = http.StatusBadGateway
+			}
+			rec := httptest.NewRecorder()
+			Router().ServeHTTP(rec, httptest.NewRequest("GET", test.url, nil))
+			assert.Equal(t, test.status, rec.Code)
+			var apiErr ApiError
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &apiErr))
+			assert.Equal(t, test.code, apiErr.Code)
+		})
+	}
+	assert.Equal(t, BreakerOpen, Search.breaker.State())
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -13,6 +16,8 @@ import (
 	mapping "github.com/dofusdude/dodumap"
 	"github.com/google/go-github/v67/github"
 	"github.com/meilisearch/meilisearch-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -20,21 +25,27 @@ var (
 	DataRepoName          = "dofus3-main"
 	MappedAlmanaxFileName = "MAPPED_ALMANAX.json"
 	Languages             = []string{"en", "fr", "de", "es", "pt"}
+	FeedDays              = 30
 )
 
-func loadAlmanaxData(version string) ([]mapping.MappedMultilangNPCAlmanax, error) {
+// loadAlmanaxData downloads the mapped almanax of a dofus3-main release and returns it with the release and asset it came from.
+func loadAlmanaxData(ctx context.Context, version string) (_ []mapping.MappedMultilangNPCAlmanax, _ ImportSource, err error) {
+	ctx, span := tracer.Start(ctx, "load almanax data", trace.WithAttributes(attribute.String("dodualm.version.requested", version)))
+	defer func() { endSpan(span, err) }()
+
 	client := github.NewClient(nil)
 
 	var repRel *github.RepositoryRelease
-	var err error
 
+	_, releaseSpan := tracer.Start(ctx, "github get release", trace.WithSpanKind(trace.SpanKindClient))
 	if version == "latest" {
-		repRel, _, err = client.Repositories.GetLatestRelease(context.Background(), DataRepoOwner, DataRepoName)
+		repRel, _, err = client.Repositories.GetLatestRelease(ctx, DataRepoOwner, DataRepoName)
 	} else {
-		repRel, _, err = client.Repositories.GetReleaseByTag(context.Background(), DataRepoOwner, DataRepoName, version)
+		repRel, _, err = client.Repositories.GetReleaseByTag(ctx, DataRepoOwner, DataRepoName, version)
 	}
+	endSpan(releaseSpan, err)
 	if err != nil {
-		return nil, err
+		return nil, ImportSource{}, err
 	}
 
 	// get the mapped almanax data
@@ -48,35 +59,54 @@ func loadAlmanaxData(version string) ([]mapping.MappedMultilangNPCAlmanax, error
 	}
 
 	if assetId == -1 {
-		return nil, fmt.Errorf("could not find asset with name %s", MappedAlmanaxFileName)
+		return nil, ImportSource{}, fmt.Errorf("could not find asset with name %s", MappedAlmanaxFileName)
 	}
 
-	log.Info("downloading asset", "assetId", assetId)
+	log.FromContext(ctx).Info("downloading asset", "assetId", assetId)
 	httpClient := &http.Client{
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			// Automatically follow all redirects
 			return nil
 		},
 	}
-	asset, redirectUrl, err := client.Repositories.DownloadReleaseAsset(context.Background(), DataRepoOwner, DataRepoName, assetId, httpClient)
+	// the asset is read completely before decoding, so the download and decode spans tell the two apart
+	raw, err := downloadReleaseAsset(ctx, client, assetId, httpClient)
 	if err != nil {
-		return nil, err
+		return nil, ImportSource{}, err
 	}
 
-	if asset == nil {
-		return nil, fmt.Errorf("asset is nil, redirect url: %s", redirectUrl)
+	_, decodeSpan := tracer.Start(ctx, "decode almanax data", trace.WithAttributes(attribute.Int("dodualm.asset.bytes", len(raw))))
+	var almData []mapping.MappedMultilangNPCAlmanax
+	err = json.Unmarshal(raw, &almData)
+	endSpan(decodeSpan, err)
+	if err != nil {
+		return nil, ImportSource{}, err
 	}
 
-	defer asset.Close()
+	return almData, ImportSource{
+		RequestedVersion: version,
+		ReleaseTag:       repRel.GetTagName(),
+		AssetId:          assetId,
+		DownloadedAt:     time.Now(),
+	}, nil
+}
 
-	var almData []mapping.MappedMultilangNPCAlmanax
-	dec := json.NewDecoder(asset)
-	err = dec.Decode(&almData)
+func downloadReleaseAsset(ctx context.Context, client *github.Client, assetId int64, httpClient *http.Client) (_ []byte, err error) {
+	ctx, span := tracer.Start(ctx, "github download asset", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int64("dodualm.asset.id", assetId)))
+	defer func() { endSpan(span, err) }()
+
+	asset, redirectUrl, err := client.Repositories.DownloadReleaseAsset(ctx, DataRepoOwner, DataRepoName, assetId, httpClient)
 	if err != nil {
 		return nil, err
 	}
 
-	return almData, nil
+	if asset == nil {
+		return nil, fmt.Errorf("asset is nil, redirect url: %s", redirectUrl)
+	}
+
+	defer asset.Close()
+	return io.ReadAll(asset)
 }
 
 /*
@@ -93,176 +123,249 @@ query params:
 - query[bonus.name] - search for bonuses by localized name and directly return the bonuses sorted by date
 */
 func RetrieveAlmanax(w http.ResponseWriter, r *http.Request) {
-	// TODO
-}
+	lang := r.Context().Value("lang").(string)
 
-// TODO use own db instead of alm/bonuses endpoint
-func UpdateAlmanaxBonusIndex(init bool) int {
-	client := meilisearch.New(MeiliHost, meilisearch.WithAPIKey(MeiliKey))
-	defer client.Close()
+	from, to, err := parseAlmanaxRange(r)
+	if err != nil {
+		writeInvalidQueryResponse(w, err.Error())
+		return
+	}
 
-	added := 0
+	page, err := parsePage(r)
+	if err != nil {
+		writeInvalidQueryResponse(w, "Invalid page: "+err.Error())
+		return
+	}
 
-	for _, lang := range Languages {
-		if lang == "pt" {
-			continue // no portuguese almanax bonuses
+	bonusType := r.URL.Query().Get("filter[bonus.type_name]")
+	almanax, total, err := getAlmanaxPage(r.Context(), from, to, bonusType, page)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query almanax: "+err.Error())
+		return
+	}
+
+	if WriteCacheHeader(&w, r, almanaxCacheInfo(almanax, lang, isImmutableRange(r.Context(), Database, from, to), total)) {
+		return
+	}
+	err = writeJson(w, newApiPage(r, renderAlmanaxList(almanax, lang), page, total))
+	if err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
+}
+
+// getAlmanaxPage serves ranges around today from the cache and pages through the database for everything else.
+func getAlmanaxPage(ctx context.Context, from, to, nameID string, page Page) ([]MappedAlmanax, int, error) {
+	if Cache.Covers(from, to) {
+		var almanax []MappedAlmanax
+		var err error
+		if nameID != "" {
+			almanax, err = Cache.GetAlmanaxByDateRangeAndNameID(ctx, from, to, nameID)
+		} else {
+			almanax, err = Cache.GetAlmanaxByDateRange(ctx, from, to)
 		}
-		url := fmt.Sprintf("https://api.dofusdu.de/dofus2/meta/%s/almanax/bonuses", lang)
-		resp, err := http.Get(url)
 		if err != nil {
-			log.Warn(err, "lang", lang)
-			return added
+			return nil, 0, err
 		}
+		return paginate(almanax, page), len(almanax), nil
+	}
 
-		var bonuses []AlmanaxBonusListing
-		err = json.NewDecoder(resp.Body).Decode(&bonuses)
-		if err != nil {
-			log.Error(err, "lang", lang)
-			return added
-		}
+	total, err := Database.CountAlmanaxByDateRange(ctx, from, to, nameID)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		var bonusesMeili []AlmanaxBonusListingMeili
-		var counter int = 0
-		for i := range bonuses {
-			bonusesMeili = append(bonusesMeili, AlmanaxBonusListingMeili{
-				Id:   strconv.Itoa(counter),
-				Slug: bonuses[i].Id,
-				Name: bonuses[i].Name,
-			})
-			counter++
-		}
+	almanax, err := Database.GetAlmanaxPageByDateRange(ctx, from, to, nameID, page.Size, page.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
 
-		indexName := fmt.Sprintf("alm-bonuses-%s", lang)
-		_, err = client.GetIndex(indexName)
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				log.Info("alm bonuses index does not exist yet, creating now", "index", indexName)
-				almTaskInfo, err := client.CreateIndex(&meilisearch.IndexConfig{
-					Uid:        indexName,
-					PrimaryKey: "id",
-				})
-
-				if err != nil {
-					log.Error("Error while creating alm bonus index in meili", "err", err)
-					return added
-				}
-
-				task, err := client.WaitForTask(almTaskInfo.TaskUID, 500*time.Millisecond)
-				if err != nil {
-					log.Error("Error while waiting alm bonus index creation at meili", "err", err)
-					return added
-				}
-
-				if task.Status == "failed" && !strings.Contains(task.Error.Message, "already exists") {
-					log.Error("alm bonuses creation failed.", "err", task.Error)
-					return added
-				}
-
-			} else {
-				log.Error("Error while getting alm bonus index in meili", "err", err)
-				return added
-			}
-		}
+	return almanax, total, nil
+}
 
-		almBonusIndex := client.Index(indexName)
+func RetrieveAlmanaxDate(w http.ResponseWriter, r *http.Request) {
+	lang := r.Context().Value("lang").(string)
+	date := r.Context().Value("date").(string)
 
-		if init { // clean index, add all
-			cleanTask, err := almBonusIndex.DeleteAllDocuments()
-			if err != nil {
-				log.Error("Error while cleaning alm bonuses in meili.", "err", err)
-				return added
-			}
+	if _, err := time.Parse(DateLayout, date); err != nil {
+		writeInvalidQueryResponse(w, "Invalid date, expected yyyy-mm-dd: "+date)
+		return
+	}
 
-			task, err := client.WaitForTask(cleanTask.TaskUID, 100*time.Millisecond)
-			if err != nil {
-				log.Error("Error while waiting for meili to clean alm bonuses.", "err", err)
-				return added
-			}
+	almanax, err := Cache.GetAlmanaxByDateRange(r.Context(), date, date)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query almanax: "+err.Error())
+		return
+	}
 
-			if task.Status == "failed" {
-				log.Error("clean alm bonuses task failed.", "err", task.Error)
-				return added
-			}
+	if len(almanax) == 0 {
+		writeNotFoundResponse(w, "No almanax for "+date)
+		return
+	}
 
-			var documentsAddTask *meilisearch.TaskInfo
-			if documentsAddTask, err = almBonusIndex.AddDocuments(bonusesMeili); err != nil {
-				log.Error("Error while adding alm bonuses to meili.", "err", err)
-				return added
-			}
+	if WriteCacheHeader(&w, r, almanaxCacheInfo(almanax, lang, isImmutableRange(r.Context(), Database, date, date), 1)) {
+		return
+	}
+	err = writeJson(w, renderAlmanax(&almanax[0], lang))
+	if err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
+}
 
-			task, err = client.WaitForTask(documentsAddTask.TaskUID, 500*time.Millisecond)
-			if err != nil {
-				log.Error("Error while waiting for meili to add alm bonuses.", "err", err)
-				return added
-			}
+func RetrieveAlmanaxIcs(w http.ResponseWriter, r *http.Request) {
+	lang := r.Context().Value("lang").(string)
 
-			if task.Status == "failed" {
-				log.Error("alm bonuses add docs task failed.", "err", task.Error)
-				return added
-			}
+	from, to := feedRange()
+	almanax, err := Cache.GetAlmanaxByDateRange(r.Context(), from, to)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query almanax: "+err.Error())
+		return
+	}
 
-			added += len(bonuses)
-		} else { // search the item exact matches before adding it
-			for _, bonus := range bonusesMeili {
-				request := &meilisearch.SearchRequest{
-					Limit: 1,
-				}
-
-				var searchResp *meilisearch.SearchResponse
-				if searchResp, err = almBonusIndex.Search(bonus.Name, request); err != nil {
-					log.Error("SearchAlmanaxBonuses: index not found: ", "err", err)
-					return added
-				}
-
-				foundIdentical := false
-				if len(searchResp.Hits) > 0 {
-					var item = searchResp.Hits[0].(map[string]interface{})
-					if item["name"] == bonus.Name {
-						foundIdentical = true
-					}
-				}
-
-				if !foundIdentical { // add only if not found
-					log.Info("adding", "bonus", bonus.Name, "bonus", bonus, "lang", lang, "hits", searchResp.Hits)
-					documentsAddTask, err := almBonusIndex.AddDocuments([]AlmanaxBonusListingMeili{bonus})
-					if err != nil {
-						log.Error("Error while adding alm bonuses to meili.", "err", err)
-						return added
-					}
-
-					task, err := client.WaitForTask(documentsAddTask.TaskUID, 500*time.Millisecond)
-					if err != nil {
-						log.Error("Error while waiting for meili to add alm bonuses.", "err", err)
-						return added
-					}
-
-					if task.Status == "failed" {
-						log.Error("alm bonuses adding failed.", "err", task.Error)
-						return added
-					}
-
-					added += 1
-				}
-			}
-		}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if writeCachingHeaders(w, r, almanaxCacheInfo(almanax, lang, false, len(almanax))) {
+		return
+	}
+	if err = writeAlmanaxIcs(w, almanax, lang); err != nil {
+		writeServerErrorResponse(w, "Could not render calendar: "+err.Error())
+		return
+	}
+}
+
+func RetrieveAlmanaxRss(w http.ResponseWriter, r *http.Request) {
+	lang := r.Context().Value("lang").(string)
+
+	from, to := feedRange()
+	almanax, err := Cache.GetAlmanaxByDateRange(r.Context(), from, to)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query almanax: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	if writeCachingHeaders(w, r, almanaxCacheInfo(almanax, lang, false, len(almanax))) {
+		return
+	}
+	if err = writeAlmanaxRss(w, almanax, lang); err != nil {
+		writeServerErrorResponse(w, "Could not render feed: "+err.Error())
+		return
+	}
+}
+
+func currentDate(timezone string) (time.Time, error) {
+	if timezone == "" {
+		timezone = ServerTz
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %s", timezone)
+	}
+	return time.Now().In(location), nil
+}
+
+// feedRange is the window of days published in the ics and rss feeds.
+func feedRange() (string, string) {
+	today, err := currentDate("")
+	if err != nil {
+		today = time.Now()
+	}
+	return today.Format(DateLayout), today.AddDate(0, 0, FeedDays).Format(DateLayout)
+}
+
+func parseAlmanaxRange(r *http.Request) (string, string, error) {
+	today, err := currentDate(r.URL.Query().Get("timezone"))
+	if err != nil {
+		return "", "", err
+	}
+
+	from := r.URL.Query().Get("range[start_date]")
+	if from == "" {
+		from = today.Format(DateLayout)
+	}
+	to := r.URL.Query().Get("range[end_date]")
+	if to == "" {
+		to = from
 	}
 
-	return added
+	fromDate, err := time.Parse(DateLayout, from)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid start date, expected yyyy-mm-dd")
+	}
+	toDate, err := time.Parse(DateLayout, to)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid end date, expected yyyy-mm-dd")
+	}
+	if toDate.Before(fromDate) {
+		return "", "", fmt.Errorf("end date is before start date")
+	}
+
+	return from, to, nil
 }
 
 type UpdateAlmanaxRequest struct {
 	// Load the mapped_almanax on startup, update with doduda API request, reload date => npc pairs from alm-dates repo.
 	// alm-dates runs short.sh etc and manages files for each year. scripts update the <year>.json if something changes.
 	// UpdateAlmanaxRequest then reads the files and updates the mapped_almanax.
+	Version string `json:"version"` // release tag of dofus3-main, default latest
 }
 
 // get webhook from github with secret and newest release tag, load the newest mapped almanax and iterate into the future, updating everything
 func UpdateAlmanax(w http.ResponseWriter, r *http.Request) {
-	// TODO
+	authorization := []byte(r.Header.Get("Authorization"))
+	if UpdateToken == "" || subtle.ConstantTimeCompare(authorization, []byte("Bearer "+UpdateToken)) != 1 {
+		writeUnauthorizedResponse(w, "Invalid update token.")
+		return
+	}
+
+	var updateRequest UpdateAlmanaxRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+			writeInvalidJsonResponse(w, err.Error())
+			return
+		}
+	}
+
+	if updateRequest.Version == "" {
+		updateRequest.Version = "latest"
+	}
+
+	// the import outlives the request, writes are serialized by the repository
+	started := Jobs.Go(r.Context(), "almanax import "+updateRequest.Version, func(ctx context.Context) error {
+		_, err := importAlmanax(ctx, updateRequest.Version)
+		return err
+	})
+	if !started {
+		writeServiceUnavailableResponse(w, "Server is shutting down.")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
 }
 
 func ListBonuses(w http.ResponseWriter, r *http.Request) {
-	// TODO
+	lang := r.Context().Value("lang").(string)
+
+	page, err := parsePage(r)
+	if err != nil {
+		writeInvalidQueryResponse(w, "Invalid page: "+err.Error())
+		return
+	}
+
+	bonusTypes, err := Database.GetBonusTypes(r.Context())
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query bonus types: "+err.Error())
+		return
+	}
+
+	if WriteCacheHeader(&w, r, bonusTypesCacheInfo(bonusTypes, lang)) {
+		return
+	}
+	err = writeJson(w, newApiPage(r, renderBonusListing(paginate(bonusTypes, page), lang), page, len(bonusTypes)))
+	if err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
 }
 
 func getLimitInBoundary(limitStr string) (int64, error) {
@@ -285,17 +388,13 @@ func SetJsonHeader(w *http.ResponseWriter) {
 	(*w).Header().Set("Content-Type", "application/json")
 }
 
-func WriteCacheHeader(w *http.ResponseWriter) {
+// WriteCacheHeader sets the JSON content type and the caching headers. It returns true if a 304 was written.
+func WriteCacheHeader(w *http.ResponseWriter, r *http.Request, info CacheInfo) bool {
 	SetJsonHeader(w)
-	//(*w).Header().Set("Cache-Control", "max-age:300, public")
-	//(*w).Header().Set("Last-Modified", LastUpdate.Format(http.TimeFormat))
-	//(*w).Header().Set("Expires", time.Now().Add(time.Minute*5).Format(http.TimeFormat))
+	return writeCachingHeaders(*w, r, info)
 }
 
 func SearchBonuses(w http.ResponseWriter, r *http.Request) {
-	client := meilisearch.New(MeiliHost, meilisearch.WithAPIKey(MeiliKey))
-	defer client.Close()
-
 	query := r.URL.Query().Get("query")
 	if query == "" {
 		writeInvalidQueryResponse(w, "Query parameter is required.")
@@ -304,11 +403,6 @@ func SearchBonuses(w http.ResponseWriter, r *http.Request) {
 
 	lang := r.Context().Value("lang").(string)
 
-	if lang == "pt" {
-		writeInvalidQueryResponse(w, "Portuguese language is not translated for Almanax Bonuses.")
-		return
-	}
-
 	var searchLimit int64
 	var err error
 	if searchLimit, err = getLimitInBoundary(r.URL.Query().Get("limit")); err != nil {
@@ -316,20 +410,22 @@ func SearchBonuses(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	index := client.Index(fmt.Sprintf("alm-bonuses-%s", lang))
-
 	request := &meilisearch.SearchRequest{
 		Limit: searchLimit,
 	}
 
-	var searchResp *meilisearch.SearchResponse
-	if searchResp, err = index.Search(query, request); err != nil {
+	searchResp, stale, err := Search.Search(r.Context(), bonusIndexName(lang), query, request)
+	if errors.Is(err, ErrSearchUnavailable) {
+		writeServiceUnavailableResponse(w, "Search is unavailable: "+err.Error())
+		return
+	}
+	if err != nil {
 		writeServerErrorResponse(w, "Could not search: "+err.Error())
 		return
 	}
-
-	//requestsTotal.Inc()
-	//requestsSearchTotal.Inc()
+	if stale {
+		w.Header().Set("Warning", `110 dodualm "Response is Stale"`)
+	}
 
 	if searchResp.EstimatedTotalHits == 0 {
 		writeNotFoundResponse(w, "No results found.")
@@ -337,19 +433,153 @@ func SearchBonuses(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var results []AlmanaxBonusListing
+	var resultIds []string
 	for _, hit := range searchResp.Hits {
-		almBonusJson := hit.(map[string]interface{})
+		encoded, err := json.Marshal(hit)
+		if err != nil {
+			writeServerErrorResponse(w, "Could not read search hit: "+err.Error())
+			return
+		}
+		var doc AlmanaxBonusListingMeili
+		if err = json.Unmarshal(encoded, &doc); err != nil {
+			writeServerErrorResponse(w, "Could not read search hit: "+err.Error())
+			return
+		}
 		almBonus := AlmanaxBonusListing{
-			Id:   almBonusJson["slug"].(string),
-			Name: almBonusJson["name"].(string),
+			Id:        doc.Slug,
+			Name:      doc.Name,
+			Fallbacks: doc.Fallbacks,
 		}
 		results = append(results, almBonus)
+		resultIds = append(resultIds, almBonus.Id+"="+almBonus.Name)
 	}
 
-	WriteCacheHeader(&w)
+	if WriteCacheHeader(&w, r, newCacheInfo(time.Time{}, false, append([]string{lang}, resultIds...)...)) {
+		return
+	}
 	err = json.NewEncoder(w).Encode(results)
 	if err != nil {
 		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
 		return
 	}
 }
+
+// meiliFilterValue quotes a value for a Meilisearch filter expression.
+func meiliFilterValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+/*
+search almanax days by tribute item, bonus type or bonus description, sorted by date
+
+query params:
+- query - search term, required
+- filter[bonus.type_name] - only days with this bonus type id
+- filter[tribute.item.subtype] - only days with this tribute item subtype
+- filter[future] - true to only return today and later days
+- page[number], page[size] - pagination
+*/
+func SearchAlmanax(w http.ResponseWriter, r *http.Request) {
+	lang := r.Context().Value("lang").(string)
+
+	query := r.URL.Query().Get("query")
+	if query == "" {
+		writeInvalidQueryResponse(w, "Query parameter is required.")
+		return
+	}
+
+	page, err := parsePage(r)
+	if err != nil {
+		writeInvalidQueryResponse(w, "Invalid page: "+err.Error())
+		return
+	}
+
+	var filters []string
+	if bonusType := r.URL.Query().Get("filter[bonus.type_name]"); bonusType != "" {
+		filters = append(filters, "bonus_type_id = "+meiliFilterValue(bonusType))
+	}
+	if subtype := r.URL.Query().Get("filter[tribute.item.subtype]"); subtype != "" {
+		filters = append(filters, "item_subtype = "+meiliFilterValue(subtype))
+	}
+	if future := r.URL.Query().Get("filter[future]"); future != "" {
+		futureOnly, err := strconv.ParseBool(future)
+		if err != nil {
+			writeInvalidFilterResponse(w, "filter[future] must be true or false.")
+			return
+		}
+		if futureOnly {
+			today, err := currentDate("")
+			if err != nil {
+				writeServerErrorResponse(w, "Could not get current date: "+err.Error())
+				return
+			}
+			todayDate, _ := time.Parse(DateLayout, today.Format(DateLayout))
+			filters = append(filters, fmt.Sprintf("timestamp >= %d", todayDate.Unix()))
+		}
+	}
+
+	request := &meilisearch.SearchRequest{
+		Page:        int64(page.Number),
+		HitsPerPage: int64(page.Size),
+		Sort:        []string{"timestamp:asc"},
+		Facets:      []string{"bonus_type_id", "item_subtype"},
+		Filter:      filters,
+	}
+
+	searchResp, stale, err := Search.Search(r.Context(), dayIndexName(lang), query, request)
+	if errors.Is(err, ErrSearchUnavailable) {
+		writeServiceUnavailableResponse(w, "Search is unavailable: "+err.Error())
+		return
+	}
+	if err != nil {
+		writeServerErrorResponse(w, "Could not search: "+err.Error())
+		return
+	}
+	if stale {
+		w.Header().Set("Warning", `110 dodualm "Response is Stale"`)
+	}
+
+	dates := make([]string, 0, len(searchResp.Hits))
+	for _, hit := range searchResp.Hits {
+		if doc, ok := hit.(map[string]interface{}); ok {
+			if date, ok := doc["date"].(string); ok {
+				dates = append(dates, date)
+			}
+		}
+	}
+
+	almanax, err := Database.GetAlmanaxByDates(r.Context(), dates)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not query almanax: "+err.Error())
+		return
+	}
+
+	var facetDistribution map[string]map[string]int
+	if encoded, err := json.Marshal(searchResp.FacetDistribution); err == nil {
+		json.Unmarshal(encoded, &facetDistribution)
+	}
+
+	total := int(searchResp.TotalHits)
+	res := AlmanaxSearchResponse{
+		ApiPage: newApiPage(r, renderAlmanaxList(almanax, lang), page, total),
+		Facets: AlmanaxSearchFacets{
+			BonusType:   facetDistribution["bonus_type_id"],
+			ItemSubtype: facetDistribution["item_subtype"],
+		},
+	}
+	if res.Facets.BonusType == nil {
+		res.Facets.BonusType = map[string]int{}
+	}
+	if res.Facets.ItemSubtype == nil {
+		res.Facets.ItemSubtype = map[string]int{}
+	}
+
+	if WriteCacheHeader(&w, r, almanaxCacheInfo(almanax, lang, false, total)) {
+		return
+	}
+	err = writeJson(w, res)
+	if err != nil {
+		writeServerErrorResponse(w, "Could not encode JSON: "+err.Error())
+		return
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAlmanaxToken(t *testing.T) {
+	token := UpdateToken
+	defer func() { UpdateToken = token }()
+
+	for _, configured := range []string{"", "secret"} {
+		UpdateToken = configured
+		for _, header := range []string{"", "Bearer ", "Bearer wrong", "Bearer secre", "secret"} {
+			req := httptest.NewRequest("POST", "/update/en/en", nil)
+			req.Header.Set("Authorization", header)
+			rec := httptest.NewRecorder()
+			UpdateAlmanax(rec, req)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, "token %q, header %q", configured, header)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+var (
+	showHeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")).Padding(0, 1)
+	showCellStyle   = lipgloss.NewStyle().Padding(0, 1)
+	showBorderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
+)
+
+// parseShowRange accepts nothing (today), a single date or a range in the form from..to.
+func parseShowRange(args []string) (string, string, error) {
+	if len(args) == 0 {
+		today, err := currentDate("")
+		if err != nil {
+			return "", "", err
+		}
+		return today.Format(DateLayout), today.Format(DateLayout), nil
+	}
+
+	from, to, isRange := strings.Cut(args[0], "..")
+	if !isRange {
+		to = from
+	}
+
+	fromDate, err := time.Parse(DateLayout, from)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid start date %s, expected yyyy-mm-dd", from)
+	}
+	toDate, err := time.Parse(DateLayout, to)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid end date %s, expected yyyy-mm-dd", to)
+	}
+	if toDate.Before(fromDate) {
+		return "", "", fmt.Errorf("end date is before start date")
+	}
+
+	return from, to, nil
+}
+
+func showRow(alm *AlmanaxResponse) []string {
+	return []string{
+		alm.Date,
+		alm.Bonus.Type.Name,
+		alm.Bonus.Description,
+		fmt.Sprintf("%s × %d", alm.Tribute.Item.Name, alm.Tribute.Quantity),
+		strconv.FormatInt(alm.RewardKamas, 10),
+	}
+}
+
+func writeShowTable(w io.Writer, almanax []AlmanaxResponse) error {
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(showBorderStyle).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return showHeaderStyle
+			}
+			return showCellStyle
+		}).
+		Headers("Date", "Bonus", "Description", "Tribute", "Kamas")
+
+	for i := range almanax {
+		t.Row(showRow(&almanax[i])...)
+	}
+
+	_, err := fmt.Fprintln(w, t.Render())
+	return err
+}
+
+func writeShowCsv(w io.Writer, almanax []AlmanaxResponse) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "bonus_type", "description", "tribute", "quantity", "kamas"}); err != nil {
+		return err
+	}
+
+	for _, alm := range almanax {
+		err := cw.Write([]string{
+			alm.Date,
+			alm.Bonus.Type.Name,
+			alm.Bonus.Description,
+			alm.Tribute.Item.Name,
+			strconv.FormatInt(alm.Tribute.Quantity, 10),
+			strconv.FormatInt(alm.RewardKamas, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseShowRange(t *testing.T) {
+	today, err := currentDate("")
+	assert.NoError(t, err)
+	from, to, err := parseShowRange(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, today.Format(DateLayout), from)
+	assert.Equal(t, today.Format(DateLayout), to)
+
+	tests := []struct {
+		arg      string
+		from, to string
+		err      string
+	}{
+		{arg: "2024-06-15", from: "2024-06-15", to: "2024-06-15"},
+		{arg: "2024-06-15..2024-06-20", from: "2024-06-15", to: "2024-06-20"},
+		{arg: "2024-06-15..2024-06-15", from: "2024-06-15", to: "2024-06-15"},
+		{arg: "2024-06-20..2024-06-15", err: "end date is before start date"},
+		{arg: "15.06.2024", err: "invalid start date 15.06.2024, expected yyyy-mm-dd"},
+		{arg: "2024-06-15..tomorrow", err: "invalid end date tomorrow, expected yyyy-mm-dd"},
+		{arg: "2024-06-15..", err: "invalid end date , expected yyyy-mm-dd"},
+	}
+	for _, test := range tests {
+		from, to, err := parseShowRange([]string{test.arg})
+		if test.err != "" {
+			assert.EqualError(t, err, test.err, test.arg)
+			continue
+		}
+		assert.NoError(t, err, test.arg)
+		assert.Equal(t, test.from, from, test.arg)
+		assert.Equal(t, test.to, to, test.arg)
+	}
+}
+
+func testShowAlmanax() []AlmanaxResponse {
+	var alm AlmanaxResponse
+	alm.Date = "2024-06-15"
+	alm.Bonus.Type.Name = "Experience"
+	alm.Bonus.Description = "More xp, for everyone"
+	alm.Tribute.Item.Name = "Wheat"
+	alm.Tribute.Quantity = 3
+	alm.RewardKamas = 1200
+	return []AlmanaxResponse{alm}
+}
+
+func TestWriteShowCsv(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeShowCsv(&buf, testShowAlmanax()))
+	assert.Equal(t, "date,bonus_type,description,tribute,quantity,kamas\n"+
+		"2024-06-15,Experience,\"More xp, for everyone\",Wheat,3,1200\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, writeShowCsv(&buf, nil))
+	assert.Equal(t, "date,bonus_type,description,tribute,quantity,kamas\n", buf.String())
+}
+
+func TestWriteShowTable(t *testing.T) {
+	assert.Equal(t, []string{"2024-06-15", "Experience", "More xp, for everyone", "Wheat × 3", "1200"}, showRow(&testShowAlmanax()[0]))
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeShowTable(&buf, testShowAlmanax()))
+	for _, cell := range []string{"Date", "Bonus", "Description", "Tribute", "Kamas", "2024-06-15", "More xp, for everyone", "Wheat × 3", "1200"} {
+		assert.Contains(t, buf.String(), cell)
+	}
+	assert.Len(t, bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")), 5, "border, header, separator, row, border")
+}
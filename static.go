@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+type StaticManifestLanguage struct {
+	Days    []string `json:"days"`
+	Months  []string `json:"months"`
+	Bonuses string   `json:"bonuses"`
+	Ics     string   `json:"ics"`
+	Rss     string   `json:"rss"`
+}
+
+type StaticManifest struct {
+	Version   string                            `json:"version"`
+	FirstDate string                            `json:"first_date,omitempty"`
+	LastDate  string                            `json:"last_date,omitempty"`
+	Languages map[string]StaticManifestLanguage `json:"languages"`
+}
+
+// staticApiPath is the API prefix the static files mirror. Their pagination links point to the live API.
+const staticApiPath = "/dofus3/v1"
+
+// the static files hold the responses without page parameters
+var firstPage = Page{Number: 1, Size: DefaultPageSize}
+
+// monthRangeQuery returns the range parameters that request the month from the API.
+func monthRangeQuery(month string) url.Values {
+	first, _ := time.Parse(DateLayout, month+"-01")
+	return url.Values{
+		"range[start_date]": {first.Format(DateLayout)},
+		"range[end_date]":   {first.AddDate(0, 1, -1).Format(DateLayout)},
+	}
+}
+
+func writeStaticFile(file string, render func(f *os.File) error) error {
+	if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = render(f); err != nil {
+		f.Close()
+		return err
+	}
+	// a failed close can leave a truncated file behind
+	return f.Close()
+}
+
+// GenerateStatic renders the read-only part of the API into outDir. The layout follows the
+// API routes below /dofus3/v1 so the directory can be served from a CDN as is.
+func GenerateStatic(repo *Repository, outDir string) (*StaticManifest, error) {
+	almanax, err := repo.GetAlmanaxByDateRange(context.Background(), "0000-01-01", "9999-12-31")
+	if err != nil {
+		return nil, err
+	}
+
+	bonusTypes, err := repo.GetBonusTypes(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	months := make(map[string][]MappedAlmanax)
+	for _, alm := range almanax {
+		month := alm.Almanax.Date[:7]
+		months[month] = append(months[month], alm)
+	}
+
+	monthKeys := make([]string, 0, len(months))
+	for month := range months {
+		monthKeys = append(monthKeys, month)
+	}
+	sort.Strings(monthKeys)
+
+	days := make([]string, 0, len(almanax))
+	for _, alm := range almanax {
+		days = append(days, alm.Almanax.Date)
+	}
+
+	manifest := &StaticManifest{
+		Version:   DodudaVersion,
+		Languages: make(map[string]StaticManifestLanguage),
+	}
+	if len(days) > 0 {
+		manifest.FirstDate = days[0]
+		manifest.LastDate = days[len(days)-1]
+	}
+
+	feedFrom, feedTo := feedRange()
+	var feedAlmanax []MappedAlmanax
+	for _, alm := range almanax {
+		if alm.Almanax.Date >= feedFrom && alm.Almanax.Date <= feedTo {
+			feedAlmanax = append(feedAlmanax, alm)
+		}
+	}
+
+	for _, lang := range Languages {
+		almanaxDir := path.Join(outDir, lang, "almanax")
+
+		for i := range almanax {
+			file := path.Join(almanaxDir, almanax[i].Almanax.Date+".json")
+			err = writeStaticFile(file, func(f *os.File) error {
+				return writeJson(f, renderAlmanax(&almanax[i], lang))
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, month := range monthKeys {
+			file := path.Join(almanaxDir, "month", month+".json")
+			err = writeStaticFile(file, func(f *os.File) error {
+				list := renderAlmanaxList(months[month], lang)
+				return writeJson(f, newApiPageAt(staticApiPath+"/"+lang+"/almanax", monthRangeQuery(month), list, firstPage, len(list)))
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		bonusesFile := path.Join(outDir, "meta", lang, "almanax", "bonuses.json")
+		err = writeStaticFile(bonusesFile, func(f *os.File) error {
+			listing := renderBonusListing(paginate(bonusTypes, firstPage), lang)
+			return writeJson(f, newApiPageAt(staticApiPath+"/meta/"+lang+"/almanax/bonuses", url.Values{}, listing, firstPage, len(bonusTypes)))
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		icsFile := path.Join(almanaxDir, "ics")
+		err = writeStaticFile(icsFile, func(f *os.File) error {
+			return writeAlmanaxIcs(f, feedAlmanax, lang)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rssFile := path.Join(almanaxDir, "rss")
+		err = writeStaticFile(rssFile, func(f *os.File) error {
+			return writeAlmanaxRss(f, feedAlmanax, lang)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		manifest.Languages[lang] = StaticManifestLanguage{
+			Days:    days,
+			Months:  monthKeys,
+			Bonuses: fmt.Sprintf("meta/%s/almanax/bonuses.json", lang),
+			Ics:     fmt.Sprintf("%s/almanax/ics", lang),
+			Rss:     fmt.Sprintf("%s/almanax/rss", lang),
+		}
+
+		log.Info("static files written", "lang", lang, "days", len(days), "months", len(monthKeys))
+	}
+
+	err = writeStaticFile(path.Join(outDir, "index.json"), func(f *os.File) error {
+		return writeJson(f, manifest)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
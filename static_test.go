@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	mapping "github.com/dofusdude/dodumap"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestRoute(t *testing.T, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	Router().ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+	assert.Equal(t, http.StatusOK, rec.Code, url)
+	return rec
+}
+
+func readStaticFile(t *testing.T, dir string, file string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(path.Join(dir, file))
+	assert.NoError(t, err, file)
+	return content
+}
+
+func TestGenerateStatic(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	_, err := repo.ImportAlmanax(context.Background(), []mapping.MappedMultilangNPCAlmanax{
+		testMappedAlmanax("Experience", "More xp", 1, 1, "2030-01-31", "2030-02-02"),
+		testMappedAlmanax("Harvest", "More crops", 2, 3, "2030-02-01"),
+	}, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01")
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	manifest, err := GenerateStatic(repo, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "2030-01-31", manifest.FirstDate)
+	assert.Equal(t, "2030-02-02", manifest.LastDate)
+
+	var index StaticManifest
+	assert.NoError(t, json.Unmarshal(readStaticFile(t, dir, "index.json"), &index))
+	assert.Equal(t, *manifest, index)
+
+	for _, lang := range Languages {
+		language := manifest.Languages[lang]
+		assert.Equal(t, []string{"2030-01-31", "2030-02-01", "2030-02-02"}, language.Days)
+		assert.Equal(t, []string{"2030-01", "2030-02"}, language.Months)
+
+		// every file is served by the route with the same path below /dofus3/v1
+		for _, day := range language.Days {
+			file := lang + "/almanax/" + day + ".json"
+			assert.Equal(t, getTestRoute(t, "/dofus3/v1/"+file[:len(file)-len(".json")]).Body.String(), string(readStaticFile(t, dir, file)))
+		}
+
+		// months are the range request for the whole month, with the same pagination links
+		february := getTestRoute(t, "/dofus3/v1/"+lang+"/almanax?range[start_date]=2030-02-01&range[end_date]=2030-02-28")
+		assert.Equal(t, february.Body.String(), string(readStaticFile(t, dir, lang+"/almanax/month/2030-02.json")))
+		january := getTestRoute(t, "/dofus3/v1/"+lang+"/almanax?range[start_date]=2030-01-01&range[end_date]=2030-01-31")
+		assert.Equal(t, january.Body.String(), string(readStaticFile(t, dir, lang+"/almanax/month/2030-01.json")))
+
+		bonuses := getTestRoute(t, "/dofus3/v1/meta/"+lang+"/almanax/bonuses")
+		assert.Equal(t, bonuses.Body.String(), string(readStaticFile(t, dir, language.Bonuses)))
+
+		assert.FileExists(t, path.Join(dir, language.Ics))
+		assert.FileExists(t, path.Join(dir, language.Rss))
+	}
+}
+
+func TestGenerateStaticBonusPages(t *testing.T) {
+	repo := newTestRepository(t)
+	useTestDatabase(t, repo)
+	var release []mapping.MappedMultilangNPCAlmanax
+	for i := 0; i <= DefaultPageSize; i++ {
+		day := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i).Format(DateLayout)
+		release = append(release, testMappedAlmanax(fmt.Sprintf("Bonus %02d", i), "More", i+1, 1, day))
+	}
+	_, err := repo.ImportAlmanax(context.Background(), release, ImportSource{ReleaseTag: "1.0.0"}, "2029-01-01")
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	manifest, err := GenerateStatic(repo, dir)
+	assert.NoError(t, err)
+
+	// the file is the first page the API serves without page parameters, the links lead to the API for the rest
+	content := readStaticFile(t, dir, manifest.Languages["en"].Bonuses)
+	assert.Equal(t, getTestRoute(t, "/dofus3/v1/meta/en/almanax/bonuses").Body.String(), string(content))
+	var bonuses ApiPage[AlmanaxBonusListing]
+	assert.NoError(t, json.Unmarshal(content, &bonuses))
+	assert.Equal(t, DefaultPageSize+1, bonuses.Total)
+	assert.Len(t, bonuses.Items, DefaultPageSize)
+	assert.NotNil(t, bonuses.Links.Next)
+}
+
+func TestWriteStaticFileError(t *testing.T) {
+	file := path.Join(t.TempDir(), "a", "b.json")
+	err := writeStaticFile(file, func(f *os.File) error {
+		return errors.New("render failed")
+	})
+	assert.EqualError(t, err, "render failed")
+
+	// rendering succeeds but closing fails, the file may be truncated
+	err = writeStaticFile(file, func(f *os.File) error {
+		return f.Close()
+	})
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
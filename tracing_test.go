@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupTracingExporters(t *testing.T) {
+	shutdown, err := setupTracing(context.Background(), "none", "", 1)
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = setupTracing(context.Background(), "zipkin", "", 1)
+	assert.Error(t, err)
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	r := chi.NewRouter()
+	r.Use(tracingMiddleware)
+	r.Get("/tracing-test/{date}", func(w http.ResponseWriter, r *http.Request) {
+		defer observeQuery(r.Context(), "tracing_test")()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest("GET", "/tracing-test/2024-06-01", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		query, server := spans[0], spans[1]
+		assert.Equal(t, "db tracing_test", query.Name)
+		assert.Equal(t, server.SpanContext.SpanID(), query.Parent.SpanID())
+		assert.Equal(t, "GET /tracing-test/{date}", server.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+		assert.Equal(t, "Error", server.Status.Code.String())
+	}
+}
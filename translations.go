@@ -0,0 +1,137 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	TranslationEntityBonusType = "bonus_types"
+	TranslationEntityBonus     = "bonus"
+	TranslationEntityTribute   = "tribute"
+)
+
+// DefaultLanguage is served when a client does not ask for a supported language.
+const DefaultLanguage = "en"
+
+// LanguageFallbacks lists the languages that are tried, in order, when a text is not translated to the requested one.
+// It is configured with LANGUAGE_FALLBACKS, see parseLanguageFallbacks.
+var LanguageFallbacks = map[string][]string{
+	"pt": {"es", "en"},
+	"es": {"en"},
+	"fr": {"en"},
+	"de": {"en"},
+}
+
+// parseLanguageFallbacks reads chains in the form "pt:es,en;es:en".
+func parseLanguageFallbacks(s string) (map[string][]string, error) {
+	fallbacks := make(map[string][]string)
+	for _, chain := range strings.Split(s, ";") {
+		chain = strings.TrimSpace(chain)
+		if chain == "" {
+			continue
+		}
+
+		lang, targets, ok := strings.Cut(chain, ":")
+		lang = strings.TrimSpace(lang)
+		if !ok || !sliceContains(Languages, lang) {
+			return nil, fmt.Errorf("invalid fallback chain %q", chain)
+		}
+
+		for _, target := range strings.Split(targets, ",") {
+			target = strings.TrimSpace(target)
+			if !sliceContains(Languages, target) || target == lang {
+				return nil, fmt.Errorf("invalid fallback %q for %s", target, lang)
+			}
+			fallbacks[lang] = append(fallbacks[lang], target)
+		}
+	}
+	return fallbacks, nil
+}
+
+// negotiateLanguage picks the supported language with the highest quality from an Accept-Language header.
+// Regional variants match their base language, so fr-CH selects fr.
+func negotiateLanguage(header string) string {
+	best, bestQuality := DefaultLanguage, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		if lang == "*" {
+			lang = DefaultLanguage
+		}
+		if quality > bestQuality && sliceContains(Languages, lang) {
+			best, bestQuality = lang, quality
+		}
+	}
+	return best
+}
+
+// Translations maps a language code to the localized text.
+type Translations map[string]string
+
+// Scan reads the json object built by json_group_object(lang, value) over the translations table.
+func (t *Translations) Scan(src any) error {
+	*t = make(Translations)
+
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case []byte:
+		return json.Unmarshal(v, t)
+	}
+
+	return fmt.Errorf("unsupported translations type %T", src)
+}
+
+// Resolve returns the text for lang, walking the fallback chain if it is missing. The second value is the
+// language the text was found in, empty if there is no translation at all.
+func (t Translations) Resolve(lang string) (string, string) {
+	if value := t[lang]; value != "" {
+		return value, lang
+	}
+
+	for _, fallback := range LanguageFallbacks[lang] {
+		if value := t[fallback]; value != "" {
+			return value, fallback
+		}
+	}
+
+	return "", ""
+}
+
+func translationsSelect(entity, idColumn string) string {
+	return fmt.Sprintf(`(SELECT json_group_object(lang, value) FROM translations WHERE entity = '%s' AND entity_id = %s)`, entity, idColumn)
+}
+
+func upsertTranslations(tx *sql.Tx, entity string, id int64, translations Translations) error {
+	query := `
+		INSERT INTO translations (entity, entity_id, lang, value, created_at, updated_at)
+		VALUES (?, ?, ?, ?, datetime('now'), datetime('now'))
+		ON CONFLICT (entity, entity_id, lang) DO UPDATE SET value = excluded.value, updated_at = datetime('now')
+		WHERE value != excluded.value`
+
+	for lang, value := range translations {
+		if value == "" {
+			continue
+		}
+		if _, err := tx.Exec(query, entity, id, lang, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
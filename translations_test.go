@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslationsResolve(t *testing.T) {
+	tr := Translations{"en": "Harvest", "es": "Cosecha", "fr": ""}
+
+	value, lang := tr.Resolve("en")
+	assert.Equal(t, "Harvest", value)
+	assert.Equal(t, "en", lang)
+
+	value, lang = tr.Resolve("pt")
+	assert.Equal(t, "Cosecha", value)
+	assert.Equal(t, "es", lang)
+
+	value, lang = tr.Resolve("fr")
+	assert.Equal(t, "Harvest", value)
+	assert.Equal(t, "en", lang)
+
+	value, lang = Translations{}.Resolve("de")
+	assert.Equal(t, "", value)
+	assert.Equal(t, "", lang)
+}
+
+func TestTranslationsScan(t *testing.T) {
+	var tr Translations
+	assert.NoError(t, tr.Scan(`{"en":"Harvest","fr":"Récolte"}`))
+	assert.Equal(t, Translations{"en": "Harvest", "fr": "Récolte"}, tr)
+
+	assert.NoError(t, tr.Scan(nil))
+	assert.Empty(t, tr)
+
+	assert.Error(t, tr.Scan(42))
+}
+
+func TestParseLanguageFallbacks(t *testing.T) {
+	fallbacks, err := parseLanguageFallbacks("pt:es,en; fr:en ;")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"pt": {"es", "en"}, "fr": {"en"}}, fallbacks)
+
+	_, err = parseLanguageFallbacks("pt:xx")
+	assert.Error(t, err)
+	_, err = parseLanguageFallbacks("en:en")
+	assert.Error(t, err)
+	_, err = parseLanguageFallbacks("pt")
+	assert.Error(t, err)
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	assert.Equal(t, "en", negotiateLanguage(""))
+	assert.Equal(t, "fr", negotiateLanguage("fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5"))
+	assert.Equal(t, "de", negotiateLanguage("nl, de;q=0.7, en;q=0.3"))
+	assert.Equal(t, "en", negotiateLanguage("nl, ja;q=0.5"))
+	assert.Equal(t, "es", negotiateLanguage("pt;q=0, es"))
+	assert.Equal(t, "pt", negotiateLanguage("PT-br"))
+}
+
+func TestRenderAlmanaxFallbacks(t *testing.T) {
+	var mapped MappedAlmanax
+	mapped.BonusType.Names = Translations{"en": "Harvest", "es": "Cosecha"}
+	mapped.Bonus.Descriptions = Translations{"pt": "Mais colheita"}
+	mapped.Tribute.ItemNames = Translations{}
+
+	res := renderAlmanax(&mapped, "pt")
+	assert.Equal(t, "Cosecha", res.Bonus.Type.Name)
+	assert.Equal(t, "Mais colheita", res.Bonus.Description)
+	assert.Equal(t, map[string]string{"bonus.type.name": "es", "tribute.item.name": ""}, res.Fallbacks)
+
+	mapped.Tribute.ItemNames = Translations{"pt": "Bwork"}
+	mapped.BonusType.Names["pt"] = "Colheita"
+	assert.Nil(t, renderAlmanax(&mapped, "pt").Fallbacks)
+}
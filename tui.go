@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	tuiTitleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	tuiWeekdayStyle  = lipgloss.NewStyle().Width(12).Foreground(lipgloss.Color("245"))
+	tuiCellStyle     = lipgloss.NewStyle().Width(12)
+	tuiEmptyStyle    = tuiCellStyle.Foreground(lipgloss.Color("238"))
+	tuiDimStyle      = tuiCellStyle.Foreground(lipgloss.Color("241"))
+	tuiSelectedStyle = tuiCellStyle.Background(lipgloss.Color("212")).Foreground(lipgloss.Color("0"))
+	tuiTodayStyle    = tuiCellStyle.Foreground(lipgloss.Color("86")).Bold(true)
+	tuiDetailStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("238")).
+				Padding(0, 1).Width(40)
+	tuiLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	tuiHelpStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	tuiErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+type tuiMonthLoadedMsg struct {
+	month time.Time
+	days  map[string]MappedAlmanax
+	err   error
+}
+
+type tuiModel struct {
+	repo       *Repository
+	today      time.Time
+	cursor     time.Time
+	month      time.Time
+	days       map[string]MappedAlmanax
+	bonusTypes []BonusType
+	filter     int // index into bonusTypes, -1 shows all
+	lang       int // index into Languages
+	err        error
+}
+
+func newTuiModel(repo *Repository, today time.Time, lang string) (tuiModel, error) {
+	bonusTypes, err := repo.GetBonusTypes(context.Background())
+	if err != nil {
+		return tuiModel{}, err
+	}
+
+	langIdx := 0
+	for i, l := range Languages {
+		if l == lang {
+			langIdx = i
+		}
+	}
+
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	return tuiModel{
+		repo:       repo,
+		today:      today,
+		cursor:     today,
+		month:      firstOfMonth(today),
+		bonusTypes: bonusTypes,
+		filter:     -1,
+		lang:       langIdx,
+	}, nil
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (m tuiModel) loadMonth() tea.Cmd {
+	month := m.month
+	repo := m.repo
+	return func() tea.Msg {
+		from := month.Format(DateLayout)
+		to := month.AddDate(0, 1, -1).Format(DateLayout)
+		almanax, err := repo.GetAlmanaxByDateRange(context.Background(), from, to)
+		days := make(map[string]MappedAlmanax)
+		for _, alm := range almanax {
+			days[alm.Almanax.Date] = alm
+		}
+		return tuiMonthLoadedMsg{month: month, days: days, err: err}
+	}
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return m.loadMonth()
+}
+
+func (m tuiModel) moveCursor(days int) (tuiModel, tea.Cmd) {
+	m.cursor = m.cursor.AddDate(0, 0, days)
+	if month := firstOfMonth(m.cursor); !month.Equal(m.month) {
+		m.month = month
+		m.days = nil
+		return m, m.loadMonth()
+	}
+	return m, nil
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tuiMonthLoadedMsg:
+		if msg.month.Equal(m.month) {
+			m.days = msg.days
+			m.err = msg.err
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "left", "h":
+			return m.moveCursor(-1)
+		case "right", "l":
+			return m.moveCursor(1)
+		case "up", "k":
+			return m.moveCursor(-7)
+		case "down", "j":
+			return m.moveCursor(7)
+		case "pgup", "[":
+			return m.moveCursor(int(m.cursor.AddDate(0, -1, 0).Sub(m.cursor).Hours() / 24))
+		case "pgdown", "]":
+			return m.moveCursor(int(m.cursor.AddDate(0, 1, 0).Sub(m.cursor).Hours() / 24))
+		case "t":
+			return m.moveCursor(int(m.today.Sub(m.cursor).Hours() / 24))
+		case "L":
+			m.lang = (m.lang + 1) % len(Languages)
+		case "f":
+			m.filter++
+			if m.filter >= len(m.bonusTypes) {
+				m.filter = -1
+			}
+		case "F":
+			m.filter--
+			if m.filter < -1 {
+				m.filter = len(m.bonusTypes) - 1
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m tuiModel) matchesFilter(alm MappedAlmanax) bool {
+	return m.filter == -1 || alm.BonusType.NameID == m.bonusTypes[m.filter].NameID
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+func (m tuiModel) viewGrid() string {
+	lang := Languages[m.lang]
+
+	var sb strings.Builder
+	for _, weekday := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		sb.WriteString(tuiWeekdayStyle.Render(weekday))
+	}
+	sb.WriteString("\n")
+
+	offset := (int(m.month.Weekday()) + 6) % 7 // weeks start on monday
+	sb.WriteString(strings.Repeat(tuiCellStyle.Render(""), offset))
+
+	col := offset
+	for day := m.month; day.Month() == m.month.Month(); day = day.AddDate(0, 0, 1) {
+		label := fmt.Sprintf("%2d", day.Day())
+		style := tuiEmptyStyle
+
+		if alm, ok := m.days[day.Format(DateLayout)]; ok {
+			label = fmt.Sprintf("%2d %s", day.Day(), truncateRunes(alm.BonusType.Name(lang), 8))
+			if m.matchesFilter(alm) {
+				style = tuiCellStyle
+			} else {
+				style = tuiDimStyle
+			}
+		}
+		if day.Equal(m.today) {
+			style = tuiTodayStyle
+		}
+		if day.Equal(m.cursor) {
+			style = tuiSelectedStyle
+		}
+
+		sb.WriteString(style.Render(label))
+		col++
+		if col%7 == 0 {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func (m tuiModel) viewDetail() string {
+	lang := Languages[m.lang]
+	date := m.cursor.Format(DateLayout)
+
+	alm, ok := m.days[date]
+	if !ok {
+		return tuiDetailStyle.Render(tuiTitleStyle.Render(date) + "\n\n" + tuiLabelStyle.Render("No almanax data for this day."))
+	}
+
+	rendered := renderAlmanax(&alm, lang)
+	lines := []string{
+		tuiTitleStyle.Render(date),
+		"",
+		tuiLabelStyle.Render("Bonus"),
+		rendered.Bonus.Type.Name,
+		rendered.Bonus.Description,
+		"",
+		tuiLabelStyle.Render("Tribute"),
+		fmt.Sprintf("%s × %d", rendered.Tribute.Item.Name, rendered.Tribute.Quantity),
+		"",
+		tuiLabelStyle.Render("Kamas"),
+		fmt.Sprintf("%d", rendered.RewardKamas),
+		"",
+		tuiLabelStyle.Render("History"),
+		"imported " + alm.Almanax.CreatedAt.Format(time.DateTime),
+	}
+	if !alm.Almanax.UpdatedAt.Equal(alm.Almanax.CreatedAt) {
+		lines = append(lines, "changed  "+alm.Almanax.UpdatedAt.Format(time.DateTime))
+	}
+
+	return tuiDetailStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (m tuiModel) View() string {
+	filter := "all bonuses"
+	if m.filter >= 0 {
+		filter = m.bonusTypes[m.filter].Name(Languages[m.lang])
+	}
+
+	header := tuiTitleStyle.Render(m.month.Format("January 2006")) +
+		tuiLabelStyle.Render(fmt.Sprintf("  ·  %s  ·  %s", strings.ToUpper(Languages[m.lang]), filter))
+
+	body := lipgloss.JoinHorizontal(lipgloss.Top, m.viewGrid(), "  ", m.viewDetail())
+
+	view := header + "\n\n" + body + "\n"
+	if m.err != nil {
+		view += tuiErrorStyle.Render(m.err.Error()) + "\n"
+	}
+	view += tuiHelpStyle.Render("←↑↓→ move · [ ] month · t today · f/F filter bonus · L language · q quit")
+
+	return view + "\n"
+}
+
+func RunTui(repo *Repository, lang string) error {
+	today, err := currentDate("")
+	if err != nil {
+		return err
+	}
+
+	model, err := newTuiModel(repo, today, lang)
+	if err != nil {
+		return err
+	}
+
+	_, err = tea.NewProgram(model, tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTuiModel() tuiModel {
+	today := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	return tuiModel{
+		today:  today,
+		cursor: today,
+		month:  firstOfMonth(today),
+		days:   map[string]MappedAlmanax{},
+		bonusTypes: []BonusType{
+			{NameID: "experience", Names: Translations{"en": "Experience"}},
+			{NameID: "harvest", Names: Translations{"en": "Harvest"}},
+		},
+		filter: -1,
+	}
+}
+
+func pressKey(t *testing.T, m tuiModel, key tea.KeyMsg) (tuiModel, tea.Cmd) {
+	t.Helper()
+	model, cmd := m.Update(key)
+	return model.(tuiModel), cmd
+}
+
+func runeKey(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestTuiMoveCursor(t *testing.T) {
+	m := newTestTuiModel()
+
+	m, cmd := pressKey(t, m, tea.KeyMsg{Type: tea.KeyRight})
+	assert.Equal(t, "2024-06-16", m.cursor.Format(DateLayout))
+	assert.Nil(t, cmd, "moving inside the month does not load anything")
+	assert.NotNil(t, m.days)
+
+	m, _ = pressKey(t, m, runeKey("k"))
+	assert.Equal(t, "2024-06-09", m.cursor.Format(DateLayout))
+
+	m, cmd = pressKey(t, m, runeKey("]"))
+	assert.Equal(t, "2024-07-09", m.cursor.Format(DateLayout))
+	assert.Equal(t, "2024-07-01", m.month.Format(DateLayout))
+	assert.NotNil(t, cmd, "a new month is loaded")
+	assert.Nil(t, m.days, "days of the previous month are not shown in the new one")
+
+	m, _ = pressKey(t, m, runeKey("t"))
+	assert.Equal(t, m.today, m.cursor)
+	assert.Equal(t, "2024-06-01", m.month.Format(DateLayout))
+
+	m, _ = pressKey(t, m, tea.KeyMsg{Type: tea.KeyPgUp})
+	assert.Equal(t, "2024-05-15", m.cursor.Format(DateLayout))
+}
+
+func TestTuiMonthLoaded(t *testing.T) {
+	m := newTestTuiModel()
+	var alm MappedAlmanax
+	alm.Almanax.Date = "2024-06-15"
+
+	stale, _ := m.Update(tuiMonthLoadedMsg{month: firstOfMonth(m.today).AddDate(0, -1, 0), days: map[string]MappedAlmanax{"2024-05-01": alm}})
+	assert.Empty(t, stale.(tuiModel).days, "a month the cursor left is dropped")
+
+	loaded, _ := m.Update(tuiMonthLoadedMsg{month: firstOfMonth(m.today), days: map[string]MappedAlmanax{"2024-06-15": alm}})
+	assert.Contains(t, loaded.(tuiModel).days, "2024-06-15")
+}
+
+func TestTuiFilterAndLanguage(t *testing.T) {
+	m := newTestTuiModel()
+
+	var filters []int
+	for i := 0; i < 3; i++ {
+		m, _ = pressKey(t, m, runeKey("f"))
+		filters = append(filters, m.filter)
+	}
+	assert.Equal(t, []int{0, 1, -1}, filters)
+
+	m, _ = pressKey(t, m, runeKey("F"))
+	assert.Equal(t, 1, m.filter, "going back from all bonuses wraps to the last type")
+
+	var harvest, experience MappedAlmanax
+	harvest.BonusType.NameID = "harvest"
+	experience.BonusType.NameID = "experience"
+	assert.True(t, m.matchesFilter(harvest))
+	assert.False(t, m.matchesFilter(experience))
+
+	for range Languages {
+		m, _ = pressKey(t, m, runeKey("L"))
+	}
+	assert.Equal(t, 0, m.lang, "the language toggle cycles through all languages")
+	m, _ = pressKey(t, m, runeKey("L"))
+	assert.Equal(t, 1, m.lang)
+}
+
+func TestTuiQuit(t *testing.T) {
+	_, cmd := pressKey(t, newTestTuiModel(), runeKey("q"))
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
+
+func TestTuiView(t *testing.T) {
+	m := newTestTuiModel()
+	view := m.View()
+	assert.Contains(t, view, "June 2024")
+	assert.Contains(t, view, "all bonuses")
+	assert.Contains(t, view, "No almanax data for this day.")
+
+	var alm MappedAlmanax
+	alm.Almanax.Date = "2024-06-15"
+	alm.BonusType = m.bonusTypes[1]
+	alm.Bonus.Descriptions = Translations{"en": "More crops"}
+	alm.Tribute.ItemNames = Translations{"en": "Wheat"}
+	alm.Tribute.Quantity = 3
+	m.days["2024-06-15"] = alm
+	m.filter = 1
+
+	view = m.View()
+	assert.Contains(t, view, "15 Harvest")
+	assert.Contains(t, view, "More crops")
+	assert.Contains(t, view, "Wheat × 3")
+	assert.Contains(t, view, "EN  ·  Harvest")
+	assert.Equal(t, "Experienc…", truncateRunes("Experience!", 10))
+}
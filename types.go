@@ -11,52 +11,43 @@ const (
 )
 
 type BonusType struct {
-	ID        int64      `db:"id"`
-	NameID    string     `db:"name_id"`
-	NameEn    string     `db:"name_en"`
-	NameFr    string     `db:"name_fr"`
-	NameEs    string     `db:"name_es"`
-	NameDe    string     `db:"name_de"`
-	NameIt    string     `db:"name_it"`
-	NamePt    string     `db:"name_pt"`
-	CreatedAt time.Time  `db:"created_at"`
-	UpdatedAt time.Time  `db:"updated_at"`
-	DeletedAt *time.Time `db:"deleted_at"`
+	ID        int64        `db:"id"`
+	NameID    string       `db:"name_id"`
+	Names     Translations `db:"-"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+	DeletedAt *time.Time   `db:"deleted_at"`
+	EditedBy  string       `db:"edited_by"` // admin of the last manual edit, empty for imported rows
+	EditedAt  *time.Time   `db:"edited_at"`
 }
 
 type Bonus struct {
-	ID            int64      `db:"id"`
-	BonusTypeID   int64      `db:"bonus_type_id"`
-	DescriptionEn string     `db:"description_en"`
-	DescriptionFr string     `db:"description_fr"`
-	DescriptionEs string     `db:"description_es"`
-	DescriptionDe string     `db:"description_de"`
-	DescriptionIt string     `db:"description_it"`
-	DescriptionPt string     `db:"description_pt"`
-	CreatedAt     time.Time  `db:"created_at"`
-	UpdatedAt     time.Time  `db:"updated_at"`
-	DeletedAt     *time.Time `db:"deleted_at"`
+	ID           int64        `db:"id"`
+	BonusTypeID  int64        `db:"bonus_type_id"`
+	Descriptions Translations `db:"-"`
+	CreatedAt    time.Time    `db:"created_at"`
+	UpdatedAt    time.Time    `db:"updated_at"`
+	DeletedAt    *time.Time   `db:"deleted_at"`
+	EditedBy     string       `db:"edited_by"`
+	EditedAt     *time.Time   `db:"edited_at"`
 }
 
 type Tribute struct {
-	ID             int64      `db:"id"`
-	ItemNameEn     string     `db:"item_name_en"`
-	ItemNameFr     string     `db:"item_name_fr"`
-	ItemNameEs     string     `db:"item_name_es"`
-	ItemNameDe     string     `db:"item_name_de"`
-	ItemNameIt     string     `db:"item_name_it"`
-	ItemNamePt     string     `db:"item_name_pt"`
-	ItemIcon       string     `db:"item_icon"`
-	ItemSd         string     `db:"item_sd"`
-	ItemHq         string     `db:"item_hq"`
-	ItemHd         string     `db:"item_hd"`
-	ItemAnkamaID   int64      `db:"item_ankama_id"`
-	ItemSubtype    string     `db:"item_subtype"`
-	ItemDoduapiUri string     `db:"item_doduapi_uri"`
-	Quantity       int64      `db:"quantity"`
-	CreatedAt      time.Time  `db:"created_at"`
-	UpdatedAt      time.Time  `db:"updated_at"`
-	DeletedAt      *time.Time `db:"deleted_at"`
+	ID             int64        `db:"id"`
+	ItemNames      Translations `db:"-"`
+	ItemIcon       string       `db:"item_icon"`
+	ItemSd         string       `db:"item_sd"`
+	ItemHq         string       `db:"item_hq"`
+	ItemHd         string       `db:"item_hd"`
+	ItemAnkamaID   int64        `db:"item_ankama_id"`
+	ItemSubtype    string       `db:"item_subtype"`
+	ItemDoduapiUri string       `db:"item_doduapi_uri"`
+	Quantity       int64        `db:"quantity"`
+	CreatedAt      time.Time    `db:"created_at"`
+	UpdatedAt      time.Time    `db:"updated_at"`
+	DeletedAt      *time.Time   `db:"deleted_at"`
+	EditedBy       string       `db:"edited_by"`
+	EditedAt       *time.Time   `db:"edited_at"`
 }
 
 type Almanax struct {
@@ -68,6 +59,73 @@ type Almanax struct {
 	CreatedAt   time.Time  `db:"created_at"`
 	UpdatedAt   time.Time  `db:"updated_at"`
 	DeletedAt   *time.Time `db:"deleted_at"`
+	EditedBy    string     `db:"edited_by"`
+	EditedAt    *time.Time `db:"edited_at"`
+}
+
+type Import struct {
+	ID                 int64      `db:"id"`
+	ReleaseTag         string     `db:"release_tag"`
+	RequestedVersion   string     `db:"requested_version"`
+	AssetId            *int64     `db:"asset_id"`      // nil for imports before provenance was recorded
+	DownloadedAt       *time.Time `db:"downloaded_at"` // nil for imports before provenance was recorded
+	Received           int64      `db:"received"`
+	Inserted           int64      `db:"inserted"`
+	Updated            int64      `db:"updated"`
+	Unchanged          int64      `db:"unchanged"`
+	Kept               int64      `db:"kept"`
+	SkippedPast        int64      `db:"skipped_past"`
+	OverridesAgreed    int64      `db:"overrides_agreed"`
+	OverridesDisagreed int64      `db:"overrides_disagreed"`
+	DodualmVersion     string     `db:"dodualm_version"`
+	ImportedAt         time.Time  `db:"imported_at"`
+}
+
+// Override pins the bonus and/or the tribute of a date over the imported data until it expires.
+type Override struct {
+	ID            int64      `db:"id"`
+	Date          string     `db:"date"`
+	BonusID       *int64     `db:"bonus_id"`   // nil keeps the imported bonus
+	TributeID     *int64     `db:"tribute_id"` // nil keeps the imported tribute
+	Reason        string     `db:"reason"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	ReleaseTag    *string    `db:"release_tag"`    // release of the latest import that had the date
+	ReleaseAgrees *bool      `db:"release_agrees"` // if that release matched the override
+	EditedBy      string     `db:"edited_by"`
+	EditedAt      *time.Time `db:"edited_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+	DeletedAt     *time.Time `db:"deleted_at"`
+}
+
+// Fields names the overridden parts of the day.
+func (o *Override) Fields() []string {
+	var fields []string
+	if o.BonusID != nil {
+		fields = append(fields, "bonus")
+	}
+	if o.TributeID != nil {
+		fields = append(fields, "tribute")
+	}
+	return fields
+}
+
+// ApiKey is a client with its own rate quota. Only the sha256 of the key is stored, the prefix identifies it in listings.
+type ApiKey struct {
+	ID            int64      `db:"id"`
+	Name          string     `db:"name"`
+	KeyHash       string     `db:"key_hash"`
+	KeyPrefix     string     `db:"key_prefix"`
+	RatePerMinute int        `db:"rate_per_minute"`
+	Burst         int        `db:"burst"`
+	CreatedAt     time.Time  `db:"created_at"`
+	LastUsedAt    *time.Time `db:"last_used_at"`
+	RevokedAt     *time.Time `db:"revoked_at"`
+	Requests      int64      // total of api_key_usage, only set by ListApiKeys
+}
+
+func (k *ApiKey) Quota() RateQuota {
+	return RateQuota{PerMinute: k.RatePerMinute, Burst: k.Burst}
 }
 
 type MappedAlmanax struct {
@@ -75,15 +133,141 @@ type MappedAlmanax struct {
 	Bonus     Bonus
 	BonusType BonusType
 	Tribute   Tribute
+	Override  *Override // the active override merged into Bonus, BonusType and Tribute, nil for imported data
 }
 
 type AlmanaxBonusListing struct {
-	Id   string `json:"id"`   // english-id
-	Name string `json:"name"` // translated text
+	Id        string            `json:"id"`   // english-id
+	Name      string            `json:"name"` // translated text
+	Fallbacks map[string]string `json:"fallbacks,omitempty"`
 }
 
 type AlmanaxBonusListingMeili struct {
-	Id   string `json:"id"`   // meili specific id without utf8 guarantees
-	Slug string `json:"slug"` // english-id
+	Id        string            `json:"id"`   // english-id restricted to the characters meili allows, see meiliDocumentId
+	Slug      string            `json:"slug"` // english-id
+	Name      string            `json:"name"` // translated text
+	Fallbacks map[string]string `json:"fallbacks,omitempty"`
+}
+
+// AlmanaxDayMeili is a document of the alm-days-{lang} index, the id is the date.
+type AlmanaxDayMeili struct {
+	Id               string `json:"id"`
+	Date             string `json:"date"`
+	Timestamp        int64  `json:"timestamp"` // midnight utc of the date, for range filters and sorting
+	BonusTypeId      string `json:"bonus_type_id"`
+	BonusTypeName    string `json:"bonus_type_name"`
+	BonusDescription string `json:"bonus_description"`
+	TributeItemName  string `json:"tribute_item_name"`
+	ItemSubtype      string `json:"item_subtype"`
+}
+
+func (b *BonusType) Name(lang string) string {
+	value, _ := b.Names.Resolve(lang)
+	return value
+}
+
+func (b *Bonus) Description(lang string) string {
+	value, _ := b.Descriptions.Resolve(lang)
+	return value
+}
+
+func (t *Tribute) ItemName(lang string) string {
+	value, _ := t.ItemNames.Resolve(lang)
+	return value
+}
+
+type ApiImageUrls struct {
+	Icon string `json:"icon"`
+	Sd   string `json:"sd,omitempty"`
+	Hq   string `json:"hq,omitempty"`
+	Hd   string `json:"hd,omitempty"`
+}
+
+type AlmanaxResponseBonusType struct {
+	Id   string `json:"id"`   // english-id
 	Name string `json:"name"` // translated text
 }
+
+type AlmanaxResponseBonus struct {
+	Description string                   `json:"description"`
+	Type        AlmanaxResponseBonusType `json:"type"`
+}
+
+type AlmanaxResponseTributeItem struct {
+	AnkamaId   int64        `json:"ankama_id"`
+	Name       string       `json:"name"`
+	Subtype    string       `json:"subtype"`
+	DoduapiUri string       `json:"doduapi_uri"`
+	ImageUrls  ApiImageUrls `json:"image_urls"`
+}
+
+type AlmanaxResponseTribute struct {
+	Item     AlmanaxResponseTributeItem `json:"item"`
+	Quantity int64                      `json:"quantity"`
+}
+
+// AlmanaxResponseOverride marks a day whose bonus or tribute was pinned by an admin over the imported data.
+type AlmanaxResponseOverride struct {
+	Fields    []string  `json:"fields"` // bonus, tribute or both
+	Reason    string    `json:"reason"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AlmanaxImportOverrides counts the active overrides the release agreed or disagreed with.
+type AlmanaxImportOverrides struct {
+	Agreed    int64 `json:"agreed"`
+	Disagreed int64 `json:"disagreed"`
+}
+
+type AlmanaxImportDays struct {
+	Received  int64 `json:"received"` // days in the downloaded asset
+	Inserted  int64 `json:"inserted"`
+	Updated   int64 `json:"updated"`
+	Unchanged int64 `json:"unchanged"`
+	Kept      int64 `json:"kept"` // differed from the release but were edited by an admin
+	// past days that differed from the release, past days are never changed
+	SkippedPast int64 `json:"skipped_past"`
+}
+
+type AlmanaxInfoResponse struct {
+	ReleaseTag       string                 `json:"release_tag"`
+	RequestedVersion string                 `json:"requested_version,omitempty"` // --game-version or the webhook version, usually latest
+	AssetId          *int64                 `json:"asset_id,omitempty"`
+	DownloadedAt     *time.Time             `json:"downloaded_at,omitempty"`
+	ImportedAt       time.Time              `json:"imported_at"`
+	Days             AlmanaxImportDays      `json:"days"`
+	Overrides        AlmanaxImportOverrides `json:"overrides"`
+	ImportedBy       string                 `json:"imported_by,omitempty"` // dodualm version that ran the import
+	ServerVersion    string                 `json:"server_version"`
+}
+
+type AlmanaxCoverageResponse struct {
+	FirstDate    string     `json:"first_date,omitempty"`
+	LastDate     string     `json:"last_date,omitempty"`
+	Days         int        `json:"days"`
+	FutureDays   int        `json:"future_days"`
+	MissingDates []string   `json:"missing_dates"`
+	ReleaseTag   string     `json:"release_tag,omitempty"`
+	ImportedAt   *time.Time `json:"imported_at,omitempty"`
+}
+
+type AlmanaxSearchFacets struct {
+	BonusType   map[string]int `json:"bonus_type"`   // bonus type id to number of days
+	ItemSubtype map[string]int `json:"item_subtype"` // tribute item subtype to number of days
+}
+
+type AlmanaxSearchResponse struct {
+	ApiPage[AlmanaxResponse]
+	Facets AlmanaxSearchFacets `json:"facets"`
+}
+
+type AlmanaxResponse struct {
+	Date        string                   `json:"date"`
+	Bonus       AlmanaxResponseBonus     `json:"bonus"`
+	Tribute     AlmanaxResponseTribute   `json:"tribute"`
+	RewardKamas int64                    `json:"reward_kamas"`
+	Override    *AlmanaxResponseOverride `json:"override,omitempty"`
+	// Fallbacks maps fields like bonus.type.name that are not translated to the requested language
+	// to the language they were taken from, empty if there is no translation at all.
+	Fallbacks map[string]string `json:"fallbacks,omitempty"`
+}
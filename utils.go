@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"strings"
+	"unicode"
 )
 
 func TruncateText(s string, max int) string {
@@ -12,6 +13,22 @@ func TruncateText(s string, max int) string {
 	return s[:strings.LastIndex(s[:max], " ")] + " ..."
 }
 
+// Slugify turns an english name into a stable, url friendly id.
+func Slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			sb.WriteRune(c)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -57,3 +57,9 @@ func TestSetSlice(t *testing.T) {
 	s.Remove("foo")
 	assert.ElementsMatch(t, []string{"bar"}, s.Slice())
 }
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "harvest", Slugify("Harvest"))
+	assert.Equal(t, "team-spirit", Slugify(" Team  Spirit! "))
+	assert.Equal(t, "l-esprit-d-equipe", Slugify("L'esprit d'equipe"))
+}